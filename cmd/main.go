@@ -3,18 +3,28 @@ package main
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"log"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	dbmigrate "shopping-api-backend-go/db"
 	"shopping-api-backend-go/docs"
+	"shopping-api-backend-go/internal/config"
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/health"
+	"shopping-api-backend-go/internal/logging"
+	"shopping-api-backend-go/internal/metrics"
+	"shopping-api-backend-go/internal/middleware"
 	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/internal/tracing"
 	"shopping-api-backend-go/web"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq" // PostgreSQL driver
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -27,91 +37,186 @@ var db *sql.DB
 // @description A simple API to manage shopping items with PostgreSQL
 // @BasePath /
 func main() {
-	log.Println("Application starting...")
-
-	// Get the ENV variable
-	env := os.Getenv("ENV")
-	if env == "" {
-		env = "development" // Default to "development" if ENV is not set
+	// Log JSON records; the level is lowered or raised once the configuration is loaded.
+	// The standard log package, used by goose among others, writes through the same logger.
+	var level slog.LevelVar
+	logger := logging.New(os.Stdout, &level)
+	slog.SetDefault(logger)
+	logger.Info("application starting")
+
+	// Load the configuration from the defaults, the env file, the environment and the flags;
+	// the remaining arguments select a subcommand
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	level.Set(cfg.LogLevel)
+	logger.Info("effective configuration", "config", cfg)
 
-	// Build the .env file path based on ENV
-	envFile := "../.env." + env
-
-	// Load the environment variables from the .env file
-	if err := godotenv.Load(envFile); err != nil {
-		log.Fatalf("Error loading .env file: %s", err)
+	// Run the migrate subcommand instead of the server if requested
+	if len(args) > 0 && args[0] == "migrate" {
+		runMigrateCommand(cfg, args[1:])
+		return
 	}
 
-	// Initialize DB connection
-	db = services.InitDB()
+	// Trace requests into the database; set up before the database is opened
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
+	}
 
-	// Create table if it doesn't exist
-	if err := services.CreateTableIfNotExists(db); err != nil {
-		log.Fatalf("Failed to create table: %v", err)
+	// Collect request, database and store metrics for /metrics
+	m := metrics.New(logger)
+
+	var store services.ItemStore
+	var checks []health.Check
+	var rateLimiter services.RateLimitStore = services.NewMemoryRateLimiter()
+	switch cfg.StoreBackend {
+	case "postgres", "sqlite":
+		var dialect string
+		db, dialect = openDatabase(cfg)
+
+		// Bring the schema up to date before serving requests
+		if cfg.AutoMigrate {
+			if err := dbmigrate.RunMigrations(db, dialect); err != nil {
+				fatal("failed to apply migrations", err)
+			}
+		}
+		sqlStore := services.NewSQLStore(db, cfg.QueryTimeout)
+		sqlStore.ObserveQueries(m.ObserveQuery)
+		m.RegisterDB(db, dialect)
+		store = sqlStore
+		migrationsCheck, err := health.Migrations(db, dialect)
+		if err != nil {
+			fatal("failed to set up the migrations check", err)
+		}
+		checks = append(checks, health.Database(db), migrationsCheck)
+		if cfg.RateLimit.Backend == "database" {
+			rateLimiter = sqlStore
+		}
+	case "memory":
+		logger.Warn("using in-memory store, data will not survive a restart")
+		store = services.NewMemoryStore()
 	}
 
-	// Initialize Gin router
-	r := web.InitializeRouter(db)
-
-	// CORS configuration
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"https://*.app.github.dev", "http://localhost:5000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"*"},
-		AllowCredentials: true,
-	}))
-
-	// Dynamically set Swagger host
-	codespaceName := os.Getenv("CODESPACE_NAME")
-	githubDomain := os.Getenv("GITHUB_COSPACE_DOMAIN")
-	var swaggerHost string
-
-	if codespaceName != "" && githubDomain != "" {
-		// Construct the base URL for GitHub Codespaces
-		swaggerHost = fmt.Sprintf("%s-8080.%s", codespaceName, githubDomain)
-	} else {
-		// Default to localhost for local development
-		swaggerHost = "localhost:8080"
+	m.RegisterStore(store)
+
+	// Gin's debug mode prints the routes and warnings as plain text, which would break the JSON log stream
+	gin.SetMode(gin.ReleaseMode)
+
+	// Initialize Gin router with handlers built on the selected store
+	h := handlers.New(store, handlers.Config{
+		IdempotencyTTL: cfg.IdempotencyTTL,
+		APIKeys:        cfg.APIKeys,
+		CORSOrigins:    cfg.CORSOrigins,
+		RateLimit: middleware.RateLimitConfig{
+			Key:   cfg.RateLimit.Key,
+			Read:  cfg.RateLimit.Read,
+			Write: cfg.RateLimit.Write,
+		},
+	})
+	h.Metrics = m
+	h.Readiness = health.NewReadiness(cfg.HealthTimeout, checks...)
+	h.RateLimiter = rateLimiter
+	r := web.InitializeRouter(h)
+
+	// Take the client address from X-Forwarded-For only behind the configured proxies,
+	// so clients can't dodge their rate limit by sending the header themselves
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		fatal("invalid trusted proxies", err)
 	}
 
 	// Set host in Swagger documentation
-	docs.SwaggerInfo.Host = swaggerHost
+	docs.SwaggerInfo.Host = cfg.Swagger()
 
 	// Swagger Endpoint
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Create an http.Server
+	// Create an http.Server. Request contexts derive from baseCtx, which is cancelled
+	// when the graceful shutdown gives up, so database queries still running are aborted.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: r,
+		Addr:        cfg.ListenAddr,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	// Go routine to start the server
 	go func() {
-		log.Println("Server starting on :8080")
+		logger.Info("server starting", "addr", cfg.ListenAddr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			fatal("failed to listen", err)
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server
+	// Wait for an interrupt, or the SIGTERM Kubernetes sends, to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutdown signal received, initiating graceful shutdown...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Fail /readyz for a while first, so load balancers stop sending requests before the server stops
+	// accepting them. A second signal skips the rest of the delay.
+	logger.Info("shutdown signal received, draining", "drain_delay", cfg.DrainDelay.String())
+	h.Readiness.Drain()
+	select {
+	case <-time.After(cfg.DrainDelay):
+	case <-quit:
+	}
+	logger.Info("initiating graceful shutdown")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v\n", err)
+		logger.Warn("server forced to shutdown", "error", err)
+	}
+	cancelRequests()
+
+	// Export the spans still buffered
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelTracing()
+	if err := shutdownTracing(tracingCtx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
 	}
 
 	// Clean up other resources like DB connections
-	if err := db.Close(); err != nil {
-		log.Printf("Error closing database connection: %v", err)
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close database connection", "error", err)
+		}
+	}
+
+	logger.Info("server exited gracefully")
+}
+
+// openDatabase opens the SQL database of the configured backend and returns it with its migration dialect
+func openDatabase(cfg config.Config) (*sql.DB, string) {
+	if cfg.StoreBackend == "sqlite" {
+		sqliteDB, err := services.OpenSQLite(cfg.DatabaseURL)
+		if err != nil {
+			fatal("failed to open SQLite database", err)
+		}
+		return sqliteDB, dbmigrate.DialectSQLite
 	}
 
-	log.Println("Server exited gracefully")
+	// Wait for the database, which may still be starting, up to the connect timeout
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Postgres.ConnectTimeout)
+	defer cancel()
+	postgresDB, err := services.InitDB(ctx, cfg.PostgresDSN(), services.PoolConfig{
+		MaxOpenConns:    cfg.Postgres.MaxOpenConns,
+		MaxIdleConns:    cfg.Postgres.MaxIdleConns,
+		ConnMaxLifetime: cfg.Postgres.ConnMaxLifetime,
+	})
+	if err != nil {
+		fatal("failed to connect to PostgreSQL", err)
+	}
+	return postgresDB, dbmigrate.DialectPostgres
+}
+
+// fatal logs an error that keeps the server from starting and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
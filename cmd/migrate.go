@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	dbmigrate "shopping-api-backend-go/db"
+	"shopping-api-backend-go/internal/config"
+)
+
+// runMigrateCommand handles "migrate up|down|status|redo" against the configured database
+func runMigrateCommand(cfg config.Config, args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: main migrate up|down|status|redo")
+		os.Exit(2)
+	}
+	if cfg.StoreBackend == "memory" {
+		fatal("cannot migrate", errors.New("the memory backend has no schema to migrate"))
+	}
+
+	migrationDB, dialect := openDatabase(cfg)
+	defer migrationDB.Close()
+
+	if err := dbmigrate.Migrate(migrationDB, dialect, args[0]); err != nil {
+		fatal("migration failed", err)
+	}
+}
@@ -1,24 +1,79 @@
-// db/migrations.go
+// db/migration.go
 package db
 
 import (
-    "database/sql"
-    "fmt"
-    "log"
-    "github.com/pressly/goose/v3"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"shopping-api-backend-go/migrations"
+
+	"github.com/pressly/goose/v3"
+)
+
+// Dialects with embedded migrations
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite3"
 )
 
-// RunMigrations applies all pending migrations from the "migrations" folder.
-func RunMigrations(db *sql.DB) error {
-    // Set the dialect to PostgreSQL
-    if err := goose.SetDialect("postgres"); err != nil {
-        return fmt.Errorf("failed to set dialect: %v", err)
-    }
+// migrationDirs maps each dialect to its directory in migrations.FS
+var migrationDirs = map[string]string{
+	DialectPostgres: "postgres",
+	DialectSQLite:   "sqlite",
+}
+
+// RunMigrations applies all pending migrations for the given dialect.
+func RunMigrations(db *sql.DB, dialect string) error {
+	return Migrate(db, dialect, "up")
+}
+
+// Migrate runs a goose command (up, down, status or redo) against the embedded migrations.
+func Migrate(db *sql.DB, dialect, command string) error {
+	dir, ok := migrationDirs[dialect]
+	if !ok {
+		return fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	// Read migrations from the binary instead of the working directory
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect(dialect); err != nil {
+		return fmt.Errorf("failed to set dialect: %v", err)
+	}
 
-    // Run the migrations from the "migrations" directory
-    if err := goose.Up(db, "migrations"); err != nil {
-        return fmt.Errorf("failed to run migrations: %v", err)
-    }
+	var err error
+	switch command {
+	case "up":
+		err = goose.Up(db, dir)
+	case "down":
+		err = goose.Down(db, dir)
+	case "status":
+		err = goose.Status(db, dir)
+	case "redo":
+		err = goose.Redo(db, dir)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down, status or redo", command)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %v", err)
+	}
+
+	return nil
+}
 
-    return nil
+// NewProvider returns a goose provider for the embedded migrations of dialect on db.
+// Its sources are read once, so it can report the version of the database again and again cheaply.
+func NewProvider(db *sql.DB, dialect string) (*goose.Provider, error) {
+	dir, ok := migrationDirs[dialect]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+	fsys, err := fs.Sub(migrations.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := goose.NewProvider(goose.Dialect(dialect), db, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %v", err)
+	}
+	return provider, nil
 }
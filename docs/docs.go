@@ -15,70 +15,82 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/shoppingItems": {
+        "/api/lists": {
             "get": {
-                "description": "Retrieve all shopping items",
+                "description": "Retrieve all shopping lists, including the default list",
                 "tags": [
-                    "Shopping Items API"
+                    "Shopping Lists API"
                 ],
-                "summary": "Get all shopping items",
+                "summary": "Get all shopping lists",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/main.ShoppingItem"
+                                "$ref": "#/definitions/models.ShoppingList"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add a new item to the shopping list",
+                "description": "Create a new, empty shopping list with a generated ID",
                 "tags": [
-                    "Shopping Items API"
+                    "Shopping Lists API"
                 ],
-                "summary": "Add a new shopping item",
+                "summary": "Create a shopping list",
                 "parameters": [
                     {
-                        "description": "New shopping item",
-                        "name": "shoppingItem",
+                        "description": "New shopping list",
+                        "name": "list",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.ShoppingItem"
+                            "$ref": "#/definitions/handlers.ListRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries of this request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/main.ShoppingItem"
+                            "$ref": "#/definitions/models.ShoppingList"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/shoppingItems/{name}": {
+        "/api/lists/{listId}": {
             "get": {
-                "description": "Retrieve a specific shopping item by its name",
+                "description": "Retrieve a specific shopping list by its ID",
                 "tags": [
-                    "Shopping Items API"
+                    "Shopping Lists API"
                 ],
-                "summary": "Get a shopping item by name",
+                "summary": "Get a shopping list by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Item name",
-                        "name": "name",
+                        "description": "List ID",
+                        "name": "listId",
                         "in": "path",
                         "required": true
                     }
@@ -87,38 +99,38 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ShoppingItem"
+                            "$ref": "#/definitions/models.ShoppingList"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update a specific shopping item by its name",
+                "description": "Change the name of a shopping list; its ID and items stay the same",
                 "tags": [
-                    "Shopping Items API"
+                    "Shopping Lists API"
                 ],
-                "summary": "Update a shopping item by name",
+                "summary": "Rename a shopping list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Item name",
-                        "name": "name",
+                        "description": "List ID",
+                        "name": "listId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated shopping item",
-                        "name": "shoppingItem",
+                        "description": "New list name",
+                        "name": "list",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.ShoppingItem"
+                            "$ref": "#/definitions/handlers.ListRequest"
                         }
                     }
                 ],
@@ -126,78 +138,1800 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ShoppingItem"
+                            "$ref": "#/definitions/models.ShoppingList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a specific shopping item by its name",
+                "description": "Delete a shopping list and all of its items. The default list cannot be deleted.",
+                "tags": [
+                    "Shopping Lists API"
+                ],
+                "summary": "Delete a shopping list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lists/{listId}/items": {
+            "get": {
+                "description": "Retrieve the shopping items of a list, optionally filtered, sorted and paginated.\nThe X-Total-Count header carries the number of matching items. When more items follow,\nX-Next-Cursor and a Link header with rel=\"next\" point to the next page.",
                 "tags": [
                     "Shopping Items API"
                 ],
-                "summary": "Delete a shopping item by name",
+                "summary": "Get all shopping items of a list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Item name",
-                        "name": "name",
+                        "description": "List ID",
+                        "name": "listId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return the item with this name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items whose name starts with this prefix",
+                        "name": "namePrefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items with this unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return checked or unchecked items",
+                        "name": "checked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return items with at least this amount",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return items with at most this amount",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "name",
+                        "description": "Sort by name or amount, prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Return the items as they were at this RFC 3339 time, reconstructed from their history",
+                        "name": "asOf",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ResponseMessage"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ShoppingItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of items matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
+                            "$ref": "#/definitions/models.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "main.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
+            },
+            "post": {
+                "description": "Add a new item to a shopping list. The item ID is generated by the server.\nWith upsert=true an item with the same name is merged instead: the amounts are added up,\nthe unit, category, notes and price that are set and the checked state are taken over.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Add a new shopping item to a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New shopping item",
+                        "name": "shoppingItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge into an existing item with the same name instead of failing",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries of this request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged into an existing item",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
                 }
             }
         },
-        "main.ResponseMessage": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
+        "/api/lists/{listId}/items/{itemId}": {
+            "get": {
+                "description": "Retrieve a specific shopping item of a list by its ID",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Get a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy; answered with 304 if it is still current",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the item"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
                 }
-            }
-        },
-        "main.ShoppingItem": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "integer",
-                    "example": 2
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Milk"
+            },
+            "put": {
+                "description": "Replace the amount and details of a shopping item. The name can only be changed through the rename endpoint.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Update a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated shopping item",
+                        "name": "shoppingItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the item"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
                 }
-            }
+            },
+            "delete": {
+                "description": "Delete a specific shopping item of a list by its ID",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Delete a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change some fields of a shopping item with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document.\nThe patch is applied atomically and the result validated like a full update. The name can only be changed through the rename endpoint.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Patch a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only patch the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the item"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lists/{listId}/items/{itemId}/decrement": {
+            "post": {
+                "description": "Atomically subtract from the amount of a shopping item, by 1 unless the body says otherwise.\nWhen the amount reaches zero the item is deleted and 204 is returned, unless atZero is clamp,\nwhich keeps the item with an amount of zero.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Decrement the amount of a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to subtract and what to do at zero",
+                        "name": "adjust",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AdjustAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lists/{listId}/items/{itemId}/increment": {
+            "post": {
+                "description": "Atomically add to the amount of a shopping item, by 1 unless the body says otherwise.\nThe amount cannot exceed 10000.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Increment the amount of a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to add",
+                        "name": "adjust",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AdjustAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lists/{listId}/items/{itemId}/rename": {
+            "post": {
+                "description": "Change the name of a shopping item; its ID stays the same",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Rename a shopping item of a list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List ID",
+                        "name": "listId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New item name",
+                        "name": "rename",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RenameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems": {
+            "get": {
+                "description": "Retrieve the shopping items of the default list, optionally filtered, sorted and paginated.\nThe X-Total-Count header carries the number of matching items. When more items follow,\nX-Next-Cursor and a Link header with rel=\"next\" point to the next page.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Get all shopping items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return the item with this name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items whose name starts with this prefix",
+                        "name": "namePrefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items with this unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return items in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return checked or unchecked items",
+                        "name": "checked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return items with at least this amount",
+                        "name": "minAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return items with at most this amount",
+                        "name": "maxAmount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "name",
+                        "description": "Sort by name or amount, prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the X-Next-Cursor header of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Return the items as they were at this RFC 3339 time, reconstructed from their history",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ShoppingItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of items matching the filters"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new item to the default list. The item ID is generated by the server.\nWith upsert=true an item with the same name is merged instead: the amounts are added up,\nthe unit, category, notes and price that are set and the checked state are taken over.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Add a new shopping item",
+                "parameters": [
+                    {
+                        "description": "New shopping item",
+                        "name": "shoppingItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Merge into an existing item with the same name instead of failing",
+                        "name": "upsert",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries of this request return the original response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged into an existing item",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems/{name}": {
+            "get": {
+                "description": "Retrieve a specific shopping item of the default list by its name",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Get a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy; answered with 304 if it is still current",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the item"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the amount and details of a shopping item. The name can only be changed through the rename endpoint.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Update a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated shopping item",
+                        "name": "shoppingItem",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only update the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the item"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a specific shopping item of the default list by its name",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Delete a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only delete the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change some fields of a shopping item with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document.\nThe patch is applied atomically and the result validated like a full update. The name can only be changed through the rename endpoint.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Patch a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merge patch object or array of JSON Patch operations",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only patch the item if it still has this ETag",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the item"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems/{name}/decrement": {
+            "post": {
+                "description": "Atomically subtract from the amount of a shopping item, by 1 unless the body says otherwise.\nWhen the amount reaches zero the item is deleted and 204 is returned, unless atZero is clamp,\nwhich keeps the item with an amount of zero.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Decrement the amount of a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to subtract and what to do at zero",
+                        "name": "adjust",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AdjustAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems/{name}/history": {
+            "get": {
+                "description": "List every change of the items that have or had the name in the default list, oldest first,\nwith the actor and time of the change and the item before and after it. Deleted items are included.\nChanges made before the history was introduced are not recorded.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Get the history of a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ItemChange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems/{name}/increment": {
+            "post": {
+                "description": "Atomically add to the amount of a shopping item, by 1 unless the body says otherwise.\nThe amount cannot exceed 10000.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Increment the amount of a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to add",
+                        "name": "adjust",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AdjustAmountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems/{name}/rename": {
+            "post": {
+                "description": "Change the name of a shopping item; its ID stays the same",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Rename a shopping item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New item name",
+                        "name": "rename",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RenameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shoppingItems:batch": {
+            "post": {
+                "description": "Run a list of add, update and delete operations on the default list in one transaction.\nIn atomic mode (the default) the first failing operation rolls back the whole batch. The response is then\na problem document with the status and code of that operation, its index in failedOperation and all results.\nIn bestEffort mode failed operations are skipped and the others are committed.\nUpdates replace the item like PUT; updates and deletes honor an optional ifMatch ETag.",
+                "tags": [
+                    "Shopping Items API"
+                ],
+                "summary": "Add, update and delete shopping items in bulk",
+                "parameters": [
+                    {
+                        "description": "Operations to run",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid batch, or an atomic batch rolled back by an operation that failed with 400",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchProblem"
+                        }
+                    },
+                    "404": {
+                        "description": "Atomic batch rolled back by an operation that failed with 404",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchProblem"
+                        }
+                    },
+                    "409": {
+                        "description": "Atomic batch rolled back by an operation that failed with 409",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchProblem"
+                        }
+                    },
+                    "412": {
+                        "description": "Atomic batch rolled back by an operation that failed with 412",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.BatchProblem"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check the health status of the API",
+                "tags": [
+                    "Health API"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "API is up and running",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Report that the process is running. It checks no dependencies, so a failing database doesn't get the pod restarted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health API"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check the database connection and the schema version, reporting each check with its latency. Fails while the server drains before a shutdown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health API"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.HealthReport"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.AdjustAmountRequest": {
+            "type": "object",
+            "properties": {
+                "atZero": {
+                    "enum": [
+                        "delete",
+                        "clamp"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/services.ZeroPolicy"
+                        }
+                    ],
+                    "example": "delete"
+                },
+                "by": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "handlers.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "ifMatch": {
+                    "type": "string",
+                    "example": "\"1\""
+                },
+                "item": {
+                    "$ref": "#/definitions/models.ShoppingItem"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Milk"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "add",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "add"
+                }
+            }
+        },
+        "handlers.BatchProblem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "item_not_found"
+                },
+                "committed": {
+                    "type": "boolean"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "item not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "failedOperation": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/shoppingItems/Milk"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchResult"
+                    }
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "handlers.BatchRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "bestEffort"
+                    ],
+                    "example": "atomic"
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchOperation"
+                    }
+                }
+            }
+        },
+        "handlers.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.BatchResult"
+                    }
+                }
+            }
+        },
+        "handlers.BatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.Problem"
+                },
+                "item": {
+                    "$ref": "#/definitions/models.ShoppingItem"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "handlers.ListRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Household"
+                }
+            }
+        },
+        "handlers.RenameRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Oat milk"
+                }
+            }
+        },
+        "models.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "min"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "amount"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Amount must be greater than zero"
+                }
+            }
+        },
+        "models.HealthReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.ItemChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "ip:203.0.113.7"
+                },
+                "after": {
+                    "$ref": "#/definitions/models.ShoppingItem"
+                },
+                "at": {
+                    "type": "string",
+                    "example": "2025-05-01T09:30:00Z"
+                },
+                "before": {
+                    "$ref": "#/definitions/models.ShoppingItem"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "itemId": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "op": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "rename",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ItemChangeOp"
+                        }
+                    ],
+                    "example": "update"
+                }
+            }
+        },
+        "models.ItemChangeOp": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "rename",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "ItemCreated",
+                "ItemUpdated",
+                "ItemRenamed",
+                "ItemDeleted"
+            ]
+        },
+        "models.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "item_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "item not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/shoppingItems/Milk"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "models.ShoppingItem": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 1,
+                    "example": 2
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "dairy"
+                },
+                "checked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "checkedAt": {
+                    "type": "string",
+                    "example": "2025-04-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Milk"
+                },
+                "notes": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Lactose free"
+                },
+                "price": {
+                    "type": "number",
+                    "maximum": 99999999,
+                    "minimum": 0,
+                    "example": 1.29
+                },
+                "unit": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "l"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.ShoppingList": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "3f2b9c1e-8d4a-4c57-9a0e-6b1f2d3c4e5f"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Household"
+                }
+            }
+        },
+        "services.ZeroPolicy": {
+            "type": "string",
+            "enum": [
+                "delete",
+                "clamp"
+            ],
+            "x-enum-comments": {
+                "ClampAtZero": "the item is kept with an amount of zero",
+                "DeleteAtZero": "the item is deleted"
+            },
+            "x-enum-varnames": [
+                "DeleteAtZero",
+                "ClampAtZero"
+            ]
         }
     }
 }`
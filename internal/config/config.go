@@ -0,0 +1,334 @@
+// Package config loads the settings of the server from defaults, an optional env file,
+// environment variables and command-line flags, in increasing order of precedence.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"shopping-api-backend-go/internal/services"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the effective configuration of the server
+type Config struct {
+	Env             string     // deployment environment, selects the default env file
+	EnvFile         string     // optional file of KEY=value lines
+	ListenAddr      string     // address the HTTP server listens on
+	LogLevel        slog.Level // least severe level that is logged
+	StoreBackend    string     // postgres, sqlite or memory; Load picks sqlite for a sqlite:// DatabaseURL if unset
+	DatabaseURL     string     // sqlite:// DSN of the SQLite backend, or a postgres:// URL replacing the Postgres connection settings
+	Postgres        PostgresConfig
+	AutoMigrate     bool          // apply pending migrations on startup
+	QueryTimeout    time.Duration // longest a database operation may take, 0 disables the limit
+	IdempotencyTTL  time.Duration // how long responses to requests with an Idempotency-Key are kept
+	APIKeys         []string      // keys clients may send in the X-API-Key header
+	ShutdownTimeout time.Duration // how long the graceful shutdown waits for running requests
+	DrainDelay      time.Duration // how long /readyz fails before the graceful shutdown starts
+	HealthTimeout   time.Duration // longest a readiness check may take
+	CORSOrigins     []string      // origins allowed to call the API from a browser; empty turns CORS off
+	TrustedProxies  []string      // addresses or CIDRs of proxies whose X-Forwarded-For is believed
+	SwaggerHost     string        // host shown in the Swagger UI, derived from the Codespace if empty
+	CodespaceName   string
+	CodespaceDomain string
+	Tracing         TracingConfig
+	RateLimit       RateLimitConfig
+}
+
+// RateLimitConfig holds the limits of the requests each client may send to the API
+type RateLimitConfig struct {
+	Backend string             // memory, or database to share the limits between servers
+	Key     string             // whose requests share a limit: ip, or api_key for the keys of API_KEYS
+	Read    services.RateLimit // requests that change nothing, such as GET
+	Write   services.RateLimit // POST, PUT, PATCH and DELETE requests
+}
+
+// TracingConfig selects where the OpenTelemetry spans of the server are exported to
+type TracingConfig struct {
+	Exporter     string  // none, otlp, stdout or file
+	File         string  // file the file exporter appends spans to
+	OTLPEndpoint string  // base URL of the OTLP/HTTP collector, such as http://localhost:4318
+	SampleRatio  float64 // share of new traces that are recorded; requests with a traceparent follow the caller
+}
+
+// PostgresConfig holds the connection settings of the PostgreSQL backend
+type PostgresConfig struct {
+	Host        string
+	Port        int
+	User        string
+	Password    string
+	DBName      string
+	SSLMode     string
+	SSLRootCert string // CA certificate file the server certificate is verified against
+
+	MaxOpenConns    int           // 0 means unlimited
+	MaxIdleConns    int           // 0 means the database/sql default of 2
+	ConnMaxLifetime time.Duration // 0 means connections are reused forever
+	ConnectTimeout  time.Duration // how long startup retries to reach the database
+}
+
+// Defaults returns the configuration used for every setting that is not set elsewhere
+func Defaults() Config {
+	return Config{
+		Env:        "development",
+		ListenAddr: ":8080",
+		Postgres: PostgresConfig{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnectTimeout:  30 * time.Second,
+		},
+		AutoMigrate:     true,
+		QueryTimeout:    services.DefaultQueryTimeout,
+		IdempotencyTTL:  24 * time.Hour,
+		ShutdownTimeout: 5 * time.Second,
+		HealthTimeout:   2 * time.Second,
+		CORSOrigins:     []string{"https://*.app.github.dev", "http://localhost:5000"},
+		RateLimit: RateLimitConfig{
+			Backend: "memory",
+			Key:     "ip",
+			Read:    services.RateLimit{Limit: 1200, Window: time.Minute},
+			Write:   services.RateLimit{Limit: 300, Window: time.Minute},
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			File:        "traces.jsonl",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// DSN returns the lib/pq connection string of the PostgreSQL settings
+func (p PostgresConfig) DSN() string {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteDSN(p.Host), p.Port, quoteDSN(p.User), quoteDSN(p.Password), quoteDSN(p.DBName), quoteDSN(p.SSLMode))
+	if p.SSLRootCert != "" {
+		dsn += " sslrootcert=" + quoteDSN(p.SSLRootCert)
+	}
+	return dsn
+}
+
+// PostgresDSN returns DatabaseURL if it is a postgres:// URL, and the DSN of the Postgres settings otherwise
+func (c Config) PostgresDSN() string {
+	if isPostgresURL(c.DatabaseURL) {
+		return c.DatabaseURL
+	}
+	return c.Postgres.DSN()
+}
+
+// isPostgresURL reports whether raw is a postgres:// or postgresql:// URL
+func isPostgresURL(raw string) bool {
+	return strings.HasPrefix(raw, "postgres://") || strings.HasPrefix(raw, "postgresql://")
+}
+
+// quoteDSN quotes a connection string value if it is empty or contains spaces, quotes or backslashes
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// Validate reports every setting that is out of range or contradicts another one
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		invalid("LISTEN_ADDR", "%q is not a host:port address", c.ListenAddr)
+	} else if _, err := strconv.Atoi(port); err != nil && port != "" {
+		invalid("LISTEN_ADDR", "port %q is not a number", port)
+	}
+
+	switch c.StoreBackend {
+	case "postgres":
+		if c.DatabaseURL != "" {
+			if u, err := url.Parse(c.DatabaseURL); err != nil || !isPostgresURL(c.DatabaseURL) || u.Host == "" {
+				invalid("DATABASE_URL", "must be a postgres:// URL with a host for the postgres backend")
+			}
+			break
+		}
+		if c.Postgres.Host == "" {
+			invalid("POSTGRES_HOST", "must be set for the postgres backend")
+		}
+		if c.Postgres.Port < 1 || c.Postgres.Port > 65535 {
+			invalid("POSTGRES_PORT", "%d is not a port between 1 and 65535", c.Postgres.Port)
+		}
+		if c.Postgres.User == "" {
+			invalid("POSTGRES_USER", "must be set for the postgres backend")
+		}
+		if c.Postgres.DBName == "" {
+			invalid("POSTGRES_DB", "must be set for the postgres backend")
+		}
+		switch c.Postgres.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			invalid("POSTGRES_SSLMODE", "%q is not one of disable, allow, prefer, require, verify-ca or verify-full", c.Postgres.SSLMode)
+		}
+	case "sqlite":
+		if strings.TrimPrefix(c.DatabaseURL, services.SQLiteScheme) == "" || !strings.HasPrefix(c.DatabaseURL, services.SQLiteScheme) {
+			invalid("DATABASE_URL", "must be a sqlite:// URL with a database path for the sqlite backend")
+		}
+	case "memory":
+	default:
+		invalid("STORE_BACKEND", "%q is not one of postgres, sqlite or memory", c.StoreBackend)
+	}
+
+	if c.StoreBackend == "postgres" {
+		if c.Postgres.MaxOpenConns < 0 {
+			invalid("DB_MAX_OPEN_CONNS", "must not be negative")
+		}
+		if c.Postgres.MaxIdleConns < 0 {
+			invalid("DB_MAX_IDLE_CONNS", "must not be negative")
+		} else if c.Postgres.MaxOpenConns > 0 && c.Postgres.MaxIdleConns > c.Postgres.MaxOpenConns {
+			invalid("DB_MAX_IDLE_CONNS", "%d is more than DB_MAX_OPEN_CONNS %d", c.Postgres.MaxIdleConns, c.Postgres.MaxOpenConns)
+		}
+		if c.Postgres.ConnMaxLifetime < 0 {
+			invalid("DB_CONN_MAX_LIFETIME", "must not be negative")
+		}
+		if c.Postgres.ConnectTimeout <= 0 {
+			invalid("DB_CONNECT_TIMEOUT", "must be positive")
+		}
+	}
+
+	if c.QueryTimeout < 0 {
+		invalid("QUERY_TIMEOUT", "must not be negative")
+	}
+	if c.IdempotencyTTL <= 0 {
+		invalid("IDEMPOTENCY_TTL", "must be positive")
+	}
+	if c.ShutdownTimeout <= 0 {
+		invalid("SHUTDOWN_TIMEOUT", "must be positive")
+	}
+	if c.DrainDelay < 0 {
+		invalid("DRAIN_DELAY", "must not be negative")
+	}
+	if c.HealthTimeout <= 0 {
+		invalid("HEALTH_CHECK_TIMEOUT", "must be positive")
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin == "" {
+			invalid("CORS_ALLOW_ORIGINS", "must not contain empty origins")
+			break
+		}
+	}
+	for _, key := range c.APIKeys {
+		if key == "" {
+			invalid("API_KEYS", "must not contain empty keys")
+			break
+		}
+		// An env file comment after an empty value is read as the value, which would make it a public key
+		if strings.HasPrefix(key, "#") {
+			invalid("API_KEYS", "must not contain keys starting with #, which are likely a comment read as a key")
+			break
+		}
+	}
+	switch c.RateLimit.Backend {
+	case "memory":
+	case "database":
+		if c.StoreBackend == "memory" {
+			invalid("RATE_LIMIT_BACKEND", "database needs the postgres or sqlite store backend")
+		}
+	default:
+		invalid("RATE_LIMIT_BACKEND", "%q is not one of memory or database", c.RateLimit.Backend)
+	}
+	switch c.RateLimit.Key {
+	case "ip":
+	case "api_key":
+		if len(c.APIKeys) == 0 {
+			invalid("RATE_LIMIT_KEY", "api_key needs the known keys in API_KEYS")
+		}
+	default:
+		invalid("RATE_LIMIT_KEY", "%q is not one of ip or api_key", c.RateLimit.Key)
+	}
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				invalid("TRUSTED_PROXIES", "%q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	case "file":
+		if c.Tracing.File == "" {
+			invalid("TRACE_FILE", "must be set for the file exporter")
+		}
+	default:
+		invalid("TRACE_EXPORTER", "%q is not one of none, otlp, stdout or file", c.Tracing.Exporter)
+	}
+	if c.Tracing.OTLPEndpoint != "" {
+		if u, err := url.Parse(c.Tracing.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			invalid("OTEL_EXPORTER_OTLP_ENDPOINT", "%q is not an http:// or https:// URL", c.Tracing.OTLPEndpoint)
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("TRACE_SAMPLE_RATIO", "%g is not between 0 and 1", c.Tracing.SampleRatio)
+	}
+	return errors.Join(errs...)
+}
+
+// Swagger returns the host shown in the Swagger UI: SwaggerHost if set, the forwarded
+// port of the GitHub Codespace if the server runs in one, and localhost otherwise
+func (c Config) Swagger() string {
+	if c.SwaggerHost != "" {
+		return c.SwaggerHost
+	}
+	_, port, _ := net.SplitHostPort(c.ListenAddr)
+	if c.CodespaceName != "" && c.CodespaceDomain != "" {
+		return fmt.Sprintf("%s-%s.%s", c.CodespaceName, port, c.CodespaceDomain)
+	}
+	return "localhost:" + port
+}
+
+// LogValue logs every setting as an attribute, with secrets redacted like in String
+func (c Config) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(settings))
+	for _, s := range settings {
+		attrs = append(attrs, slog.String(s.key, c.display(s)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// String lists every setting as KEY=value, one per line, with secrets redacted
+func (c Config) String() string {
+	var b strings.Builder
+	for _, s := range settings {
+		fmt.Fprintf(&b, "%s=%s\n", s.key, c.display(s))
+	}
+	return b.String()
+}
+
+// display returns the value of a setting with secrets redacted
+func (c Config) display(s setting) string {
+	value := s.get(&c)
+	switch {
+	case s.secret && value != "":
+		return redacted
+	case s.key == "DATABASE_URL":
+		return redactURL(value)
+	}
+	return value
+}
+
+// redacted replaces secrets in String
+const redacted = "[REDACTED]"
+
+// redactURL hides the password of a URL with credentials
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
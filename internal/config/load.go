@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"shopping-api-backend-go/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// setting is one configuration value, named by its environment variable and, if it has one, its flag
+type setting struct {
+	key    string
+	flag   string
+	usage  string
+	secret bool
+	bool   bool // the flag may be given without a value
+	list   bool // an empty value is an empty list rather than unset
+	set    func(c *Config, value string) error
+	get    func(c *Config) string
+}
+
+// settings lists every configuration value in the order String prints them
+var settings = []setting{
+	stringSetting("ENV", "env", "deployment environment, selects the default env file ../.env.<env>", func(c *Config) *string { return &c.Env }),
+	stringSetting("ENV_FILE", "env-file", "file of KEY=value lines to load; a missing default file is skipped", func(c *Config) *string { return &c.EnvFile }),
+	stringSetting("LISTEN_ADDR", "listen", "address the HTTP server listens on", func(c *Config) *string { return &c.ListenAddr }),
+	levelSetting("LOG_LEVEL", "log-level", "least severe level that is logged: debug, info, warn or error", func(c *Config) *slog.Level { return &c.LogLevel }),
+	stringSetting("STORE_BACKEND", "store-backend", "storage backend: postgres, sqlite or memory", func(c *Config) *string { return &c.StoreBackend }),
+	stringSetting("DATABASE_URL", "database-url", "database URL, such as sqlite:///var/lib/shopping.db or postgres://user@host/db?sslmode=verify-full", func(c *Config) *string { return &c.DatabaseURL }),
+	stringSetting("POSTGRES_HOST", "postgres-host", "PostgreSQL host", func(c *Config) *string { return &c.Postgres.Host }),
+	intSetting("POSTGRES_PORT", "postgres-port", "PostgreSQL port", func(c *Config) *int { return &c.Postgres.Port }),
+	stringSetting("POSTGRES_USER", "postgres-user", "PostgreSQL user", func(c *Config) *string { return &c.Postgres.User }),
+	secretSetting(stringSetting("POSTGRES_PASSWORD", "", "", func(c *Config) *string { return &c.Postgres.Password })),
+	stringSetting("POSTGRES_DB", "postgres-db", "PostgreSQL database name", func(c *Config) *string { return &c.Postgres.DBName }),
+	stringSetting("POSTGRES_SSLMODE", "postgres-sslmode", "PostgreSQL sslmode: disable, allow, prefer, require, verify-ca or verify-full", func(c *Config) *string { return &c.Postgres.SSLMode }),
+	stringSetting("POSTGRES_SSLROOTCERT", "postgres-sslrootcert", "CA certificate file the PostgreSQL server certificate is verified against", func(c *Config) *string { return &c.Postgres.SSLRootCert }),
+	intSetting("DB_MAX_OPEN_CONNS", "db-max-open-conns", "most open database connections, 0 for unlimited", func(c *Config) *int { return &c.Postgres.MaxOpenConns }),
+	intSetting("DB_MAX_IDLE_CONNS", "db-max-idle-conns", "most idle database connections kept in the pool", func(c *Config) *int { return &c.Postgres.MaxIdleConns }),
+	durationSetting("DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "longest a database connection is reused, 0 for forever", func(c *Config) *time.Duration { return &c.Postgres.ConnMaxLifetime }),
+	durationSetting("DB_CONNECT_TIMEOUT", "db-connect-timeout", "how long startup retries to reach the database", func(c *Config) *time.Duration { return &c.Postgres.ConnectTimeout }),
+	boolSetting("AUTO_MIGRATE", "auto-migrate", "apply pending database migrations on startup", func(c *Config) *bool { return &c.AutoMigrate }),
+	durationSetting("QUERY_TIMEOUT", "query-timeout", "longest a database operation may take, 0 disables the limit", func(c *Config) *time.Duration { return &c.QueryTimeout }),
+	durationSetting("IDEMPOTENCY_TTL", "idempotency-ttl", "how long responses to requests with an Idempotency-Key are kept", func(c *Config) *time.Duration { return &c.IdempotencyTTL }),
+	secretSetting(listSetting("API_KEYS", "", "", func(c *Config) *[]string { return &c.APIKeys })),
+	durationSetting("SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long the graceful shutdown waits for running requests", func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+	durationSetting("DRAIN_DELAY", "drain-delay", "how long /readyz fails before the graceful shutdown starts", func(c *Config) *time.Duration { return &c.DrainDelay }),
+	durationSetting("HEALTH_CHECK_TIMEOUT", "health-check-timeout", "longest a readiness check may take", func(c *Config) *time.Duration { return &c.HealthTimeout }),
+	listSetting("CORS_ALLOW_ORIGINS", "cors-allow-origins", "comma-separated origins allowed to call the API from a browser", func(c *Config) *[]string { return &c.CORSOrigins }),
+	listSetting("TRUSTED_PROXIES", "trusted-proxies", "comma-separated addresses or CIDRs of proxies whose X-Forwarded-For header gives the client address", func(c *Config) *[]string { return &c.TrustedProxies }),
+	stringSetting("RATE_LIMIT_BACKEND", "rate-limit-backend", "where rate limits are kept: memory, or database to share them between servers", func(c *Config) *string { return &c.RateLimit.Backend }),
+	stringSetting("RATE_LIMIT_KEY", "rate-limit-key", "whose requests share a rate limit: ip or api_key", func(c *Config) *string { return &c.RateLimit.Key }),
+	rateSetting("RATE_LIMIT_READ", "rate-limit-read", "requests that change nothing each client may send, such as 1200/1m, or off", func(c *Config) *services.RateLimit { return &c.RateLimit.Read }),
+	rateSetting("RATE_LIMIT_WRITE", "rate-limit-write", "POST, PUT, PATCH and DELETE requests each client may send, such as 300/1m, or off", func(c *Config) *services.RateLimit { return &c.RateLimit.Write }),
+	stringSetting("TRACE_EXPORTER", "trace-exporter", "where spans are exported to: none, otlp, stdout or file", func(c *Config) *string { return &c.Tracing.Exporter }),
+	stringSetting("TRACE_FILE", "trace-file", "file the file exporter appends spans to", func(c *Config) *string { return &c.Tracing.File }),
+	stringSetting("OTEL_EXPORTER_OTLP_ENDPOINT", "otlp-endpoint", "base URL of the OTLP/HTTP collector, http://localhost:4318 if unset", func(c *Config) *string { return &c.Tracing.OTLPEndpoint }),
+	floatSetting("TRACE_SAMPLE_RATIO", "trace-sample-ratio", "share of new traces that are recorded, between 0 and 1", func(c *Config) *float64 { return &c.Tracing.SampleRatio }),
+	stringSetting("SWAGGER_HOST", "swagger-host", "host shown in the Swagger UI", func(c *Config) *string { return &c.SwaggerHost }),
+	stringSetting("CODESPACE_NAME", "", "", func(c *Config) *string { return &c.CodespaceName }),
+	stringSetting("GITHUB_COSPACE_DOMAIN", "", "", func(c *Config) *string { return &c.CodespaceDomain }),
+}
+
+// Load builds the configuration from the defaults, the env file, the environment and the flags in args,
+// each overriding the ones before, and validates it. It returns the arguments left after the flags.
+// The env file is ENV_FILE, or ../.env.<ENV> if that is unset; only an explicitly named file must exist.
+func Load(args []string) (Config, []string, error) {
+	flags, rest, err := parseFlags(args)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	env := lookupAll(os.LookupEnv)
+
+	// The environment and the flags decide which file to read
+	cfg := Defaults()
+	for _, layer := range []map[string]string{env, flags} {
+		if value, ok := layer["ENV"]; ok {
+			cfg.Env = value
+		}
+		if value, ok := layer["ENV_FILE"]; ok {
+			cfg.EnvFile = value
+		}
+	}
+	explicit := cfg.EnvFile != ""
+	if !explicit {
+		cfg.EnvFile = "../.env." + cfg.Env
+	}
+	raw, err := godotenv.Read(cfg.EnvFile)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+		cfg.EnvFile = ""
+	case err != nil:
+		return Config{}, nil, fmt.Errorf("reading env file: %w", err)
+	}
+	file := lookupAll(func(key string) (string, bool) { value, ok := raw[key]; return value, ok })
+
+	// Apply the layers in increasing precedence and report every bad value at once
+	var errs []error
+	for _, layer := range []struct {
+		source string
+		values map[string]string
+	}{{"env file", file}, {"environment", env}, {"flag", flags}} {
+		for _, s := range settings {
+			if value, ok := layer.values[s.key]; ok && s.key != "ENV" && s.key != "ENV_FILE" {
+				if err := s.set(&cfg, value); err != nil {
+					errs = append(errs, fmt.Errorf("%s from %s: %w", s.key, layer.source, err))
+				}
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, nil, err
+	}
+
+	if cfg.StoreBackend == "" {
+		cfg.StoreBackend = "postgres"
+		if strings.HasPrefix(cfg.DatabaseURL, services.SQLiteScheme) {
+			cfg.StoreBackend = "sqlite"
+		}
+	}
+	return cfg, rest, cfg.Validate()
+}
+
+// parseFlags parses the settings given as flags in args and returns them by key with the remaining arguments
+func parseFlags(args []string) (map[string]string, []string, error) {
+	set := flag.NewFlagSet("shopping-api", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	values := map[string]string{}
+	for _, s := range settings {
+		if s.flag != "" {
+			set.Var(&flagValue{setting: s, values: values}, s.flag, s.usage)
+		}
+	}
+	if err := set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			set.SetOutput(os.Stderr)
+			set.PrintDefaults()
+		}
+		return nil, nil, err
+	}
+	return lookupAll(func(key string) (string, bool) { value, ok := values[key]; return value, ok }), set.Args(), nil
+}
+
+// lookupAll returns the settings that lookup finds, by key. Empty values count as unset,
+// except for lists, so that CORS_ALLOW_ORIGINS= can clear the default origins.
+func lookupAll(lookup func(string) (string, bool)) map[string]string {
+	values := map[string]string{}
+	for _, s := range settings {
+		if value, ok := lookup(s.key); ok && (value != "" || s.list) {
+			values[s.key] = value
+		}
+	}
+	return values
+}
+
+// flagValue records a flag under the key of its setting; the value is parsed along with the other layers
+type flagValue struct {
+	setting setting
+	values  map[string]string
+}
+
+// String returns no default; the defaults live in Defaults
+func (f *flagValue) String() string { return "" }
+
+// IsBoolFlag lets boolean flags go without a value
+func (f *flagValue) IsBoolFlag() bool { return f.setting.bool }
+
+// Set records the value of the flag
+func (f *flagValue) Set(value string) error {
+	f.values[f.setting.key] = value
+	return nil
+}
+
+// stringSetting returns a setting for a string field
+func stringSetting(key, flag, usage string, field func(c *Config) *string) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error { *field(c) = value; return nil },
+		get: func(c *Config) string { return *field(c) },
+	}
+}
+
+// intSetting returns a setting for an integer field
+func intSetting(key, flag, usage string, field func(c *Config) *int) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%q is not an integer", value)
+			}
+			*field(c) = n
+			return nil
+		},
+		get: func(c *Config) string { return strconv.Itoa(*field(c)) },
+	}
+}
+
+// floatSetting returns a setting for a floating-point field
+func floatSetting(key, flag, usage string, field func(c *Config) *float64) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", value)
+			}
+			*field(c) = f
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatFloat(*field(c), 'g', -1, 64) },
+	}
+}
+
+// boolSetting returns a setting for a boolean field
+func boolSetting(key, flag, usage string, field func(c *Config) *bool) setting {
+	return setting{
+		key: key, flag: flag, usage: usage, bool: true,
+		set: func(c *Config, value string) error {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%q is not true or false", value)
+			}
+			*field(c) = b
+			return nil
+		},
+		get: func(c *Config) string { return strconv.FormatBool(*field(c)) },
+	}
+}
+
+// durationSetting returns a setting for a duration field
+func durationSetting(key, flag, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%q is not a duration such as 5s", value)
+			}
+			*field(c) = d
+			return nil
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// levelSetting returns a setting for a log level field
+func levelSetting(key, flag, usage string, field func(c *Config) *slog.Level) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error {
+			if err := field(c).UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("%q is not one of debug, info, warn or error", value)
+			}
+			return nil
+		},
+		get: func(c *Config) string { return strings.ToLower(field(c).String()) },
+	}
+}
+
+// rateSetting returns a setting for a rate limit field
+func rateSetting(key, flag, usage string, field func(c *Config) *services.RateLimit) setting {
+	return setting{
+		key: key, flag: flag, usage: usage,
+		set: func(c *Config, value string) error {
+			limit, err := services.ParseRateLimit(value)
+			if err != nil {
+				return err
+			}
+			*field(c) = limit
+			return nil
+		},
+		get: func(c *Config) string { return field(c).String() },
+	}
+}
+
+// listSetting returns a setting for a comma-separated list field. An empty value sets an empty list.
+func listSetting(key, flag, usage string, field func(c *Config) *[]string) setting {
+	return setting{
+		key: key, flag: flag, usage: usage, list: true,
+		set: func(c *Config, value string) error {
+			var list []string
+			if value != "" {
+				list = strings.Split(value, ",")
+				for i := range list {
+					list[i] = strings.TrimSpace(list[i])
+				}
+			}
+			*field(c) = list
+			return nil
+		},
+		get: func(c *Config) string { return strings.Join(*field(c), ",") },
+	}
+}
+
+// secretSetting marks a setting whose value String redacts. Secrets have no flag,
+// since command lines show up in process listings.
+func secretSetting(s setting) setting {
+	s.secret = true
+	return s
+}
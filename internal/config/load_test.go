@@ -0,0 +1,264 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every setting in the environment for the duration of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, s := range settings {
+		t.Setenv(s.key, "") // restores the variable after the test
+		os.Unsetenv(s.key)
+	}
+}
+
+// writeEnvFile writes an env file of lines in a temporary directory and returns its path
+func writeEnvFile(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeEnvFile(t,
+		"STORE_BACKEND=memory",
+		"LISTEN_ADDR=:1000",
+		"QUERY_TIMEOUT=1s",
+		"IDEMPOTENCY_TTL=1h",
+		"LOG_LEVEL=warn",
+	)
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, cfg Config)
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{"STORE_BACKEND": "memory"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":8080" || cfg.QueryTimeout != Defaults().QueryTimeout || cfg.EnvFile != "" {
+					t.Errorf("got %q, %s, env file %q; want the defaults and no env file", cfg.ListenAddr, cfg.QueryTimeout, cfg.EnvFile)
+				}
+			},
+		},
+		{
+			name: "env file over defaults",
+			env:  map[string]string{"ENV_FILE": file},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":1000" || cfg.QueryTimeout != time.Second || cfg.IdempotencyTTL != time.Hour || cfg.LogLevel != slog.LevelWarn {
+					t.Errorf("got %q, %s, %s, %s; want the env file", cfg.ListenAddr, cfg.QueryTimeout, cfg.IdempotencyTTL, cfg.LogLevel)
+				}
+			},
+		},
+		{
+			name: "environment over env file",
+			env:  map[string]string{"ENV_FILE": file, "LISTEN_ADDR": ":2000", "QUERY_TIMEOUT": "2s"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":2000" || cfg.QueryTimeout != 2*time.Second || cfg.IdempotencyTTL != time.Hour {
+					t.Errorf("got %q, %s, %s; want the environment and the env file for the rest", cfg.ListenAddr, cfg.QueryTimeout, cfg.IdempotencyTTL)
+				}
+			},
+		},
+		{
+			name: "flags over environment",
+			env:  map[string]string{"LISTEN_ADDR": ":2000", "QUERY_TIMEOUT": "2s"},
+			args: []string{"-env-file", file, "-listen", ":3000", "migrate", "up"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":3000" || cfg.QueryTimeout != 2*time.Second || cfg.EnvFile != file {
+					t.Errorf("got %q, %s, env file %q; want the flags and the environment for the rest", cfg.ListenAddr, cfg.QueryTimeout, cfg.EnvFile)
+				}
+			},
+		},
+		{
+			name: "empty values are unset",
+			env:  map[string]string{"STORE_BACKEND": "memory", "LISTEN_ADDR": "", "QUERY_TIMEOUT": ""},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.ListenAddr != ":8080" || cfg.QueryTimeout != Defaults().QueryTimeout {
+					t.Errorf("got %q, %s; want the defaults", cfg.ListenAddr, cfg.QueryTimeout)
+				}
+			},
+		},
+		{
+			name: "lists",
+			env:  map[string]string{"STORE_BACKEND": "memory", "CORS_ALLOW_ORIGINS": "https://shop.example, http://localhost:5000", "API_KEYS": "key-one,key-two"},
+			check: func(t *testing.T, cfg Config) {
+				if want := []string{"https://shop.example", "http://localhost:5000"}; !reflect.DeepEqual(cfg.CORSOrigins, want) {
+					t.Errorf("CORSOrigins = %q, want %q", cfg.CORSOrigins, want)
+				}
+				if want := []string{"key-one", "key-two"}; !reflect.DeepEqual(cfg.APIKeys, want) {
+					t.Errorf("APIKeys = %q, want %q", cfg.APIKeys, want)
+				}
+			},
+		},
+		{
+			name: "empty list turns CORS off",
+			env:  map[string]string{"STORE_BACKEND": "memory", "CORS_ALLOW_ORIGINS": ""},
+			check: func(t *testing.T, cfg Config) {
+				if len(cfg.CORSOrigins) != 0 {
+					t.Errorf("CORSOrigins = %q, want none", cfg.CORSOrigins)
+				}
+			},
+		},
+		{
+			name: "empty list flag over environment",
+			env:  map[string]string{"STORE_BACKEND": "memory", "CORS_ALLOW_ORIGINS": "https://shop.example"},
+			args: []string{"-cors-allow-origins="},
+			check: func(t *testing.T, cfg Config) {
+				if len(cfg.CORSOrigins) != 0 {
+					t.Errorf("CORSOrigins = %q, want none", cfg.CORSOrigins)
+				}
+			},
+		},
+		{
+			name: "sample env file",
+			env:  map[string]string{"ENV_FILE": "../../.env.sample"},
+			check: func(t *testing.T, cfg Config) {
+				if len(cfg.APIKeys) != 0 {
+					t.Errorf("APIKeys = %q, want none", cfg.APIKeys)
+				}
+			},
+		},
+		{
+			name: "sqlite picked from the database URL",
+			env:  map[string]string{"DATABASE_URL": "sqlite:///tmp/shop.db"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.StoreBackend != "sqlite" {
+					t.Errorf("StoreBackend = %q, want sqlite", cfg.StoreBackend)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg, rest, err := Load(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.args) > 2 && strings.Join(rest, " ") != "migrate up" {
+				t.Errorf("remaining arguments = %q, want migrate up", rest)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want []string // parts of the error
+	}{
+		{"missing explicit env file", map[string]string{"ENV_FILE": "/nonexistent/.env"}, nil, []string{"reading env file"}},
+		{"unparsable values", map[string]string{"STORE_BACKEND": "memory", "QUERY_TIMEOUT": "soon", "POSTGRES_PORT": "db"},
+			nil, []string{"QUERY_TIMEOUT from environment", "POSTGRES_PORT from environment"}},
+		{"unparsable flag", map[string]string{"STORE_BACKEND": "memory"}, []string{"-idempotency-ttl", "long"}, []string{"IDEMPOTENCY_TTL from flag"}},
+		{"unparsable rate limit", map[string]string{"STORE_BACKEND": "memory"}, []string{"-rate-limit-read", "lots"}, []string{"RATE_LIMIT_READ from flag"}},
+		{"unknown flag", nil, []string{"-no-such-flag"}, []string{"no-such-flag"}},
+		{"unknown log level", map[string]string{"STORE_BACKEND": "memory", "LOG_LEVEL": "loud"}, nil, []string{"LOG_LEVEL from environment"}},
+		{"invalid after loading", map[string]string{"STORE_BACKEND": "memory", "SHUTDOWN_TIMEOUT": "0s"}, nil, []string{"SHUTDOWN_TIMEOUT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, _, err := Load(tt.args)
+			if err == nil {
+				t.Fatal("Load() succeeded, want an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string // keys of the invalid settings, none if valid
+	}{
+		{"memory defaults", func(c *Config) {}, nil},
+		{"postgres without user and database", func(c *Config) { c.StoreBackend = "postgres" }, []string{"POSTGRES_USER", "POSTGRES_DB"}},
+		{"postgres URL", func(c *Config) { c.StoreBackend = "postgres"; c.DatabaseURL = "postgres://shop@db/shop" }, nil},
+		{"postgres with a sqlite URL", func(c *Config) { c.StoreBackend = "postgres"; c.DatabaseURL = "sqlite:///shop.db" }, []string{"DATABASE_URL"}},
+		{"more idle than open connections", func(c *Config) {
+			c.StoreBackend = "postgres"
+			c.DatabaseURL = "postgres://shop@db/shop"
+			c.Postgres.MaxOpenConns = 2
+		}, []string{"DB_MAX_IDLE_CONNS"}},
+		{"sqlite without path", func(c *Config) { c.StoreBackend = "sqlite"; c.DatabaseURL = "sqlite://" }, []string{"DATABASE_URL"}},
+		{"unknown backend", func(c *Config) { c.StoreBackend = "mongo" }, []string{"STORE_BACKEND"}},
+		{"bad listen address", func(c *Config) { c.ListenAddr = "8080" }, []string{"LISTEN_ADDR"}},
+		{"non-positive durations", func(c *Config) { c.IdempotencyTTL = 0; c.ShutdownTimeout = -time.Second }, []string{"IDEMPOTENCY_TTL", "SHUTDOWN_TIMEOUT"}},
+		{"no CORS origins", func(c *Config) { c.CORSOrigins = nil }, nil},
+		{"empty entries", func(c *Config) { c.CORSOrigins = []string{"http://localhost:5000", ""}; c.APIKeys = []string{""} }, []string{"CORS_ALLOW_ORIGINS", "API_KEYS"}},
+		{"comment read as API key", func(c *Config) { c.APIKeys = []string{"# comma-separated API keys"} }, []string{"API_KEYS"}},
+		{"database rate limits without database", func(c *Config) { c.RateLimit.Backend = "database" }, []string{"RATE_LIMIT_BACKEND"}},
+		{"api_key without keys", func(c *Config) { c.RateLimit.Key = "api_key" }, []string{"RATE_LIMIT_KEY"}},
+		{"api_key with keys", func(c *Config) { c.RateLimit.Key = "api_key"; c.APIKeys = []string{"secret"} }, nil},
+		{"user rate limit key", func(c *Config) { c.RateLimit.Key = "user" }, []string{"RATE_LIMIT_KEY"}},
+		{"bad trusted proxy", func(c *Config) { c.TrustedProxies = []string{"10.0.0.0/8", "proxy"} }, []string{"TRUSTED_PROXIES"}},
+		{"file exporter without file", func(c *Config) { c.Tracing.Exporter = "file"; c.Tracing.File = "" }, []string{"TRACE_FILE"}},
+		{"sample ratio above 1", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, []string{"TRACE_SAMPLE_RATIO"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Defaults()
+			cfg.StoreBackend = "memory"
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors for %v", tt.want)
+			}
+			lines := strings.Split(err.Error(), "\n")
+			if len(lines) != len(tt.want) {
+				t.Errorf("Validate() = %v, want %d errors", err, len(tt.want))
+			}
+			for _, key := range tt.want {
+				if !strings.Contains(err.Error(), key+":") {
+					t.Errorf("Validate() = %v, want an error for %s", err, key)
+				}
+			}
+		})
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	cfg := Defaults()
+	cfg.Postgres.Password = "hunter2"
+	cfg.APIKeys = []string{"key-one", "key-two"}
+	cfg.DatabaseURL = "postgres://shop:hunter2@db/shop"
+	s := cfg.String()
+	for _, secret := range []string{"hunter2", "key-one", "key-two"} {
+		if strings.Contains(s, secret) {
+			t.Errorf("String() shows %q:\n%s", secret, s)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithServiceError writes the problem response matching a service or validation error
+func (h *Handler) respondWithServiceError(c *gin.Context, err error, fallback string) {
+	utils.RespondWithProblem(c, h.problem(c.Request.Context(), err, fallback))
+}
+
+// problem returns the problem details of err like serviceProblem and logs the server errors,
+// whose details the client never sees, together with the ID of the request
+func (h *Handler) problem(ctx context.Context, err error, fallback string) models.Problem {
+	problem := serviceProblem(err, fallback)
+	if problem.Status >= http.StatusInternalServerError {
+		h.Logger.ErrorContext(ctx, fallback, "status", problem.Status, "code", problem.Code, "error", err)
+	}
+	return problem
+}
+
+// serviceProblem returns the problem details matching the kind of a service error, or the invalid
+// fields of an invalidFields error. Errors of no known kind are internal and become a 500 with the fallback message.
+func serviceProblem(err error, fallback string) models.Problem {
+	var invalid invalidFields
+	if errors.As(err, &invalid) {
+		return utils.NewValidationProblem(invalid)
+	}
+
+	code := services.ErrorCode(err)
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return utils.NewProblem(http.StatusNotFound, code, err.Error())
+	case errors.Is(err, services.ErrAlreadyExists), errors.Is(err, services.ErrConflict):
+		return utils.NewProblem(http.StatusConflict, code, err.Error())
+	case errors.Is(err, services.ErrPreconditionFailed):
+		return utils.NewProblem(http.StatusPreconditionFailed, code, err.Error())
+	case errors.Is(err, services.ErrValidation):
+		return utils.NewProblem(http.StatusBadRequest, code, err.Error())
+	case errors.Is(err, services.ErrTimeout):
+		return utils.NewProblem(http.StatusGatewayTimeout, code, err.Error())
+	case errors.Is(err, services.ErrUnavailable):
+		return utils.NewProblem(http.StatusServiceUnavailable, code, err.Error())
+	}
+	return utils.NewProblem(http.StatusInternalServerError, "internal_error", fallback)
+}
+
+// respondWithInvalidPayload writes a 400 for a request body that could not be decoded
+func respondWithInvalidPayload(c *gin.Context) {
+	utils.RespondWithError(c, http.StatusBadRequest, "invalid_payload", "Invalid request payload")
+}
+
+// fieldError returns a validation error of a single request field
+func fieldError(field, code, message string) models.FieldError {
+	return models.FieldError{Field: field, Code: code, Message: message}
+}
+
+// NoRoute answers requests for unknown paths
+func NoRoute(c *gin.Context) {
+	utils.RespondWithError(c, http.StatusNotFound, "route_not_found", "No route matches "+c.Request.URL.Path)
+}
+
+// NoMethod answers requests for known paths with an unsupported method
+func NoMethod(c *gin.Context) {
+	utils.RespondWithError(c, http.StatusMethodNotAllowed, "method_not_allowed", c.Request.Method+" is not supported on "+c.Request.URL.Path)
+}
+
+// Recover logs the panic of a handler and answers its request with a 500
+func (h *Handler) Recover(c *gin.Context, p any) {
+	h.Logger.ErrorContext(c.Request.Context(), "handler panicked", "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+	utils.RespondWithError(c, http.StatusInternalServerError, "internal_error", "Internal server error")
+}
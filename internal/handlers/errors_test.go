@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/web"
+)
+
+// TestProblemResponses checks that errors of every kind are answered with RFC 7807 problem details
+func TestProblemResponses(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+		fields []string // the invalid fields listed in errors
+	}{
+		{http.MethodGet, "/api/shoppingItems/Bread", "", http.StatusNotFound, "item_not_found", nil},
+		{http.MethodGet, "/api/lists/missing/items", "", http.StatusNotFound, "list_not_found", nil},
+		{http.MethodPost, "/api/shoppingItems", `{"name":"Milk","amount":1}`, http.StatusConflict, "item_already_exists", nil},
+		{http.MethodPost, "/api/shoppingItems", `{"name":`, http.StatusBadRequest, "invalid_payload", nil},
+		{http.MethodPost, "/api/shoppingItems", `{"name":"","amount":0,"price":-1}`, http.StatusBadRequest, "validation_failed", []string{"name", "amount", "price"}},
+		{http.MethodDelete, "/api/lists/default", "", http.StatusConflict, "default_list_protected", nil},
+		{http.MethodGet, "/api/nothing", "", http.StatusNotFound, "route_not_found", nil},
+		{http.MethodPatch, "/api/lists", "", http.StatusMethodNotAllowed, "method_not_allowed", nil},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(t)
+		w := serve(router, tt.method, tt.path, tt.body, nil)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path, w.Code, tt.status, w.Body)
+			continue
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s %s: Content-Type %q, want application/problem+json", tt.method, tt.path, ct)
+		}
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("%s %s: %v: %s", tt.method, tt.path, err, w.Body)
+		}
+		if problem.Code != tt.code || problem.Status != tt.status || problem.Type != "about:blank" ||
+			problem.Title != http.StatusText(tt.status) || problem.Instance != tt.path {
+			t.Errorf("%s %s: problem %+v, want code %s for status %d", tt.method, tt.path, problem, tt.code, tt.status)
+		}
+		var fields []string
+		for _, field := range problem.Errors {
+			fields = append(fields, field.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s %s: invalid fields %v, want %v", tt.method, tt.path, fields, tt.fields)
+		}
+	}
+}
+
+// failingStore is an ItemStore whose item lookups by name fail with err
+type failingStore struct {
+	services.ItemStore
+	err error
+}
+
+// GetItemByName fails with the error of the store
+func (s failingStore) GetItemByName(ctx context.Context, listID, name string) (models.ShoppingItem, error) {
+	return models.ShoppingItem{}, s.err
+}
+
+// TestStoreFailures checks that slow and cancelled store operations are answered with 504 and 503
+func TestStoreFailures(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{&services.Error{Kind: services.ErrTimeout, Code: "query_timeout", Message: "the database did not answer in time"}, http.StatusGatewayTimeout, "query_timeout"},
+		{&services.Error{Kind: services.ErrUnavailable, Code: "request_cancelled", Message: "the request was cancelled"}, http.StatusServiceUnavailable, "request_cancelled"},
+	}
+	for _, tt := range tests {
+		h := handlers.New(failingStore{ItemStore: services.NewMemoryStore(), err: tt.err}, handlers.Config{})
+		router := web.InitializeRouter(h)
+
+		w := serve(router, http.MethodGet, "/api/shoppingItems/Milk", "", nil)
+		var problem models.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || w.Code != tt.status || problem.Code != tt.code {
+			t.Errorf("%v: status %d, body %s; want %d with code %s", tt.err, w.Code, w.Body, tt.status, tt.code)
+		}
+	}
+}
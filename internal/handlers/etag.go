@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// itemETag returns the entity tag of the current version of an item
+func itemETag(item models.ShoppingItem) string {
+	return fmt.Sprintf(`"%d"`, item.Version)
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists etag or is "*".
+// If-Match compares strongly, so weak tags only match when weak is set.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns an ItemCheck enforcing the If-Match header of the request, or nil if it has none
+func ifMatch(c *gin.Context) services.ItemCheck {
+	return ifMatchCheck(c.GetHeader("If-Match"))
+}
+
+// ifMatchCheck returns an ItemCheck passing items whose ETag is listed in an If-Match header value,
+// or nil if the value is empty
+func ifMatchCheck(header string) services.ItemCheck {
+	if header == "" {
+		return nil
+	}
+	return func(item models.ShoppingItem) error {
+		if etagMatches(header, itemETag(item), false) {
+			return nil
+		}
+		return &services.Error{
+			Kind:    services.ErrPreconditionFailed,
+			Code:    "version_mismatch",
+			Message: fmt.Sprintf("item has changed, its current ETag is %s", itemETag(item)),
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// The Milk item of newTestRouter is at version 1
+func TestGetItemIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusOK},
+		{`"1"`, http.StatusNotModified},
+		{`W/"1"`, http.StatusNotModified}, // If-None-Match compares weakly
+		{`"2"`, http.StatusOK},
+		{`"2", "1"`, http.StatusNotModified},
+		{`"2",W/"1"`, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{"1", http.StatusOK}, // not a quoted tag
+	}
+	router := newTestRouter(t)
+	for _, tt := range tests {
+		w := serve(router, http.MethodGet, "/api/shoppingItems/Milk", "", map[string]string{"If-None-Match": tt.header})
+		if w.Code != tt.want {
+			t.Errorf("If-None-Match %s: status %d, want %d", tt.header, w.Code, tt.want)
+		}
+		if etag := w.Header().Get("ETag"); etag != `"1"` {
+			t.Errorf("If-None-Match %s: ETag %s, want \"1\"", tt.header, etag)
+		}
+	}
+}
+
+func TestWriteIfMatch(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		header string
+		want   int
+	}{
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, "", http.StatusOK},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, `"1"`, http.StatusOK},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, `"0", "1"`, http.StatusOK},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, "*", http.StatusOK},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, `"2"`, http.StatusPreconditionFailed},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":2}`, `W/"1"`, http.StatusPreconditionFailed}, // If-Match compares strongly
+		{http.MethodPatch, "/api/shoppingItems/Milk", `{"amount":3}`, `"1"`, http.StatusOK},
+		{http.MethodPatch, "/api/shoppingItems/Milk", `{"amount":3}`, `"7"`, http.StatusPreconditionFailed},
+		{http.MethodDelete, "/api/shoppingItems/Milk", "", `"7"`, http.StatusPreconditionFailed},
+		{http.MethodDelete, "/api/shoppingItems/Milk", "", `"1"`, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(t)
+		header := map[string]string{"If-Match": tt.header}
+		if tt.method == http.MethodPatch {
+			header["Content-Type"] = "application/merge-patch+json"
+		}
+		w := serve(router, tt.method, tt.path, tt.body, header)
+		if w.Code != tt.want {
+			t.Errorf("%s with If-Match %s: status %d, want %d: %s", tt.method, tt.header, w.Code, tt.want, w.Body)
+			continue
+		}
+		if w.Code == http.StatusOK {
+			if etag := w.Header().Get("ETag"); etag != `"2"` {
+				t.Errorf("%s with If-Match %s: ETag %s, want \"2\"", tt.method, tt.header, etag)
+			}
+		}
+		if w.Code == http.StatusPreconditionFailed && !strings.Contains(w.Body.String(), "version_mismatch") {
+			t.Errorf("%s with If-Match %s: body %s, want version_mismatch", tt.method, tt.header, w.Body)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log/slog"
+	"shopping-api-backend-go/internal/health"
+	"shopping-api-backend-go/internal/metrics"
+	"shopping-api-backend-go/internal/middleware"
+	"shopping-api-backend-go/internal/services"
+	"time"
+)
+
+// Config holds the settings of the HTTP API
+type Config struct {
+	IdempotencyTTL time.Duration // how long responses to requests with an Idempotency-Key are kept
+	APIKeys        []string      // the keys clients may send in the X-API-Key header
+	CORSOrigins    []string      // origins allowed to call the API from a browser; none turns CORS off
+	RateLimit      middleware.RateLimitConfig
+}
+
+// Handler serves the HTTP API from the dependencies it is built with. Nothing is shared
+// between handlers, so several of them can serve different stores in one process.
+type Handler struct {
+	Store  services.ItemStore
+	Logger *slog.Logger
+	Now    func() time.Time // clock used for timestamps such as CheckedAt
+	Config Config
+
+	// Metrics, if set, records every request and is served on /metrics
+	Metrics *metrics.Metrics
+
+	// Readiness, if set, decides the answer of /readyz; without it the server is always ready
+	Readiness *health.Readiness
+
+	// RateLimiter, if set, keeps the token buckets that limit the requests of each client
+	RateLimiter services.RateLimitStore
+}
+
+// New returns a Handler serving the store, logging to the default slog logger and using the system clock
+func New(store services.ItemStore, config Config) *Handler {
+	return &Handler{Store: store, Logger: slog.Default(), Now: time.Now, Config: config}
+}
@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/web"
+)
+
+func TestCORS(t *testing.T) {
+	preflight := map[string]string{"Origin": "https://shop.app.github.dev", "Access-Control-Request-Method": http.MethodPost}
+	tests := []struct {
+		name    string
+		origins []string
+		want    string // Access-Control-Allow-Origin of the responses
+		status  int    // of a GET from the origin
+	}{
+		{"allowed origin", []string{"https://*.app.github.dev"}, "https://shop.app.github.dev", http.StatusOK},
+		{"other origin", []string{"http://localhost:5000"}, "", http.StatusForbidden},
+		{"CORS off", nil, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handlers.New(services.NewMemoryStore(), handlers.Config{CORSOrigins: tt.origins})
+			router := web.InitializeRouter(h)
+			w := serve(router, http.MethodOptions, "/api/shoppingItems", "", preflight)
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+
+			// Actual requests to the API routes carry the headers too
+			w = serve(router, http.MethodGet, "/api/shoppingItems", "", map[string]string{"Origin": "https://shop.app.github.dev"})
+			if got := w.Header().Get("Access-Control-Allow-Origin"); w.Code != tt.status || got != tt.want {
+				t.Errorf("GET: status %d, Access-Control-Allow-Origin %q; want %d and %q", w.Code, got, tt.status, tt.want)
+			}
+			if tt.want != "" && w.Header().Get("Access-Control-Expose-Headers") == "" {
+				t.Error("GET: no Access-Control-Expose-Headers")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/health"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/web"
+)
+
+// TestProbes checks that /readyz reports every check, fails with any of them and fails while draining,
+// while /livez keeps passing
+func TestProbes(t *testing.T) {
+	var dbErr error
+	readiness := health.NewReadiness(time.Second,
+		health.Check{Name: "database", Run: func(context.Context) error { return dbErr }},
+		health.Check{Name: "migrations", Run: func(context.Context) error { return nil }},
+	)
+	h := handlers.New(services.NewMemoryStore(), handlers.Config{})
+	h.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	h.Readiness = readiness
+	router := web.InitializeRouter(h)
+
+	steps := []struct {
+		name    string
+		prepare func()
+		path    string
+		status  int
+		want    string
+		failing []string // names of the checks reported as failing
+	}{
+		{"ready", func() {}, "/readyz", http.StatusOK, models.HealthOK, nil},
+		{"database down", func() { dbErr = errors.New("connection refused") }, "/readyz", http.StatusServiceUnavailable, models.HealthFailing, []string{"database"}},
+		{"alive with the database down", func() {}, "/livez", http.StatusOK, models.HealthOK, nil},
+		{"database back", func() { dbErr = nil }, "/readyz", http.StatusOK, models.HealthOK, nil},
+		{"draining", readiness.Drain, "/readyz", http.StatusServiceUnavailable, models.HealthDraining, nil},
+		{"alive while draining", func() {}, "/livez", http.StatusOK, models.HealthOK, nil},
+	}
+	for _, step := range steps {
+		step.prepare()
+		w := serve(router, http.MethodGet, step.path, "", nil)
+		if w.Code != step.status {
+			t.Fatalf("%s: status %d, want %d: %s", step.name, w.Code, step.status, w.Body)
+		}
+		var report models.HealthReport
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v: %s", step.name, err, w.Body)
+		}
+		if report.Status != step.want {
+			t.Errorf("%s: status %q, want %q", step.name, report.Status, step.want)
+		}
+		var failing []string
+		for _, check := range report.Checks {
+			if check.Status != models.HealthOK {
+				failing = append(failing, check.Name)
+				if check.Error == "" {
+					t.Errorf("%s: failing check %s has no error", step.name, check.Name)
+				}
+			}
+		}
+		if !reflect.DeepEqual(failing, step.failing) {
+			t.Errorf("%s: failing checks %q, want %q", step.name, failing, step.failing)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/pkg/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batch modes
+const (
+	BatchAtomic     = "atomic"     // all operations succeed or none is applied
+	BatchBestEffort = "bestEffort" // failed operations are skipped, the others are applied
+)
+
+// BatchOperation is one operation of a batch request. Updates and deletes address an item
+// by its ID or by its name.
+type BatchOperation struct {
+	Op      string               `json:"op" binding:"oneof=add update delete" example:"add"`
+	ID      string               `json:"id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Name    string               `json:"name,omitempty" example:"Milk"`
+	IfMatch string               `json:"ifMatch,omitempty" example:"\"1\""`
+	Item    *models.ShoppingItem `json:"item,omitempty" binding:"-"`
+}
+
+// BatchRequest is the request body of the batch endpoint
+type BatchRequest struct {
+	Mode       string           `json:"mode,omitempty" binding:"omitempty,oneof=atomic bestEffort" example:"atomic"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+// BatchResult is the outcome of one operation, with the item on success or the problem on failure
+type BatchResult struct {
+	Status int                  `json:"status" example:"201"`
+	Item   *models.ShoppingItem `json:"item,omitempty"`
+	Error  *models.Problem      `json:"error,omitempty"`
+}
+
+// BatchResponse lists the outcome of every operation in request order
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchProblem is the problem details response of an atomic batch that was rolled back. It takes over
+// the problem of the failed operation and carries its index and the outcome of every operation as extension members.
+type BatchProblem struct {
+	models.Problem
+	BatchResponse
+	FailedOperation int `json:"failedOperation" example:"1"`
+}
+
+// errBatchAborted rolls back an atomic batch after an operation failed
+var errBatchAborted = errors.New("batch aborted")
+
+// BatchItems adds, updates and deletes many shopping items in one transaction
+// @Summary Add, update and delete shopping items in bulk
+// @Description Run a list of add, update and delete operations on the default list in one transaction.
+// @Description In atomic mode (the default) the first failing operation rolls back the whole batch. The response is then
+// @Description a problem document with the status and code of that operation, its index in failedOperation and all results.
+// @Description In bestEffort mode failed operations are skipped and the others are committed.
+// @Description Updates replace the item like PUT; updates and deletes honor an optional ifMatch ETag.
+// @Tags Shopping Items API
+// @Param batch body BatchRequest true "Operations to run"
+// @Success 200 {object} BatchResponse
+// @Failure 400 {object} BatchProblem "Invalid batch, or an atomic batch rolled back by an operation that failed with 400"
+// @Failure 404 {object} BatchProblem "Atomic batch rolled back by an operation that failed with 404"
+// @Failure 409 {object} BatchProblem "Atomic batch rolled back by an operation that failed with 409"
+// @Failure 412 {object} BatchProblem "Atomic batch rolled back by an operation that failed with 412"
+// @Router /api/shoppingItems:batch [post]
+func (h *Handler) BatchItems(c *gin.Context) {
+	var req BatchRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+	if !validate(c, &req) {
+		return
+	}
+
+	// Run every operation in one transaction. Best-effort operations get a savepoint each,
+	// so a failure only undoes that operation.
+	ctx := c.Request.Context()
+	response := BatchResponse{Results: make([]BatchResult, len(req.Operations))}
+	failed, now := -1, h.Now()
+	err := h.Store.WithinTx(ctx, func(tx services.ItemStore) error {
+		for i, op := range req.Operations {
+			if req.Mode == BatchBestEffort {
+				err := tx.WithinTx(ctx, func(opTx services.ItemStore) error {
+					response.Results[i] = h.runBatchOperation(ctx, opTx, op, now)
+					if response.Results[i].Error != nil {
+						return errBatchAborted
+					}
+					return nil
+				})
+				if err != nil && !errors.Is(err, errBatchAborted) {
+					return err
+				}
+				continue
+			}
+
+			response.Results[i] = h.runBatchOperation(ctx, tx, op, now)
+			if response.Results[i].Error != nil {
+				failed = i
+				markRolledBack(response.Results, i)
+				return errBatchAborted
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		h.respondWithServiceError(c, err, "Failed to run batch")
+		return
+	}
+
+	// A rolled back batch fails like its failed operation, with the outcome of every operation attached
+	if failed >= 0 {
+		problem := BatchProblem{Problem: *response.Results[failed].Error, BatchResponse: response, FailedOperation: failed}
+		problem.Instance = c.Request.URL.RequestURI()
+		c.Header("Content-Type", utils.ProblemContentType)
+		c.JSON(problem.Status, problem)
+		return
+	}
+
+	// Return the outcome of every operation
+	response.Committed = true
+	c.JSON(http.StatusOK, response)
+}
+
+// runBatchOperation applies one operation to the default list of the store
+func (h *Handler) runBatchOperation(ctx context.Context, store services.ItemStore, op BatchOperation, now time.Time) BatchResult {
+	listID := services.DefaultListID
+	if op.Op == "add" {
+		if op.Item == nil {
+			return h.batchFailure(ctx, invalidFields{fieldError("item", "required", "item cannot be empty")})
+		}
+		item, err := addItem(ctx, store, listID, *op.Item, now)
+		if err != nil {
+			return h.batchFailure(ctx, err)
+		}
+		return BatchResult{Status: http.StatusCreated, Item: &item}
+	}
+
+	// Updates and deletes work on an existing item
+	var item models.ShoppingItem
+	var err error
+	switch {
+	case op.ID != "":
+		item, err = store.GetItem(ctx, listID, op.ID)
+	case op.Name != "":
+		item, err = store.GetItemByName(ctx, listID, op.Name)
+	default:
+		return h.batchFailure(ctx, invalidFields{fieldError("id", "required", "id or name is required")})
+	}
+	if err != nil {
+		return h.batchFailure(ctx, err)
+	}
+
+	check := ifMatchCheck(op.IfMatch)
+	if op.Op == "delete" {
+		if err := store.DeleteItem(ctx, listID, item.ID, check); err != nil {
+			return h.batchFailure(ctx, err)
+		}
+		return BatchResult{Status: http.StatusNoContent}
+	}
+
+	if op.Item == nil {
+		return h.batchFailure(ctx, invalidFields{fieldError("item", "required", "item cannot be empty")})
+	}
+	updatedItem, err := replaceItem(ctx, store, listID, item, *op.Item, check, now)
+	if err != nil {
+		return h.batchFailure(ctx, err)
+	}
+	return BatchResult{Status: http.StatusOK, Item: &updatedItem}
+}
+
+// batchFailure describes an operation that failed with err
+func (h *Handler) batchFailure(ctx context.Context, err error) BatchResult {
+	problem := h.problem(ctx, err, "Failed to run operation")
+	return BatchResult{Status: problem.Status, Error: &problem}
+}
+
+// markRolledBack records that the operations of an atomic batch were undone or never run
+// because the operation at index failed
+func markRolledBack(results []BatchResult, failed int) {
+	for i := range results {
+		switch {
+		case i < failed:
+			problem := utils.NewProblem(http.StatusFailedDependency, "batch_rolled_back", "rolled back because another operation failed")
+			results[i] = BatchResult{Status: problem.Status, Error: &problem}
+		case i > failed:
+			problem := utils.NewProblem(http.StatusFailedDependency, "batch_not_run", "not run because another operation failed")
+			results[i] = BatchResult{Status: problem.Status, Error: &problem}
+		}
+	}
+}
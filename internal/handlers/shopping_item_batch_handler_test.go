@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"shopping-api-backend-go/internal/handlers"
+)
+
+func TestBatchItems(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		want          int
+		wantCommitted bool
+		wantStatuses  []int
+		wantBread     bool
+	}{
+		{"atomic", `{"operations":[{"op":"add","item":{"name":"Bread","amount":1}},{"op":"update","name":"Milk","item":{"amount":2}}]}`,
+			http.StatusOK, true, []int{http.StatusCreated, http.StatusOK}, true},
+		{"atomic failure", `{"operations":[{"op":"add","item":{"name":"Bread","amount":1}},{"op":"delete","name":"Eggs"}]}`,
+			http.StatusNotFound, false, []int{http.StatusFailedDependency, http.StatusNotFound}, false},
+		{"best effort", `{"mode":"bestEffort","operations":[{"op":"add","item":{"name":"Bread","amount":1}},{"op":"delete","name":"Eggs"}]}`,
+			http.StatusOK, true, []int{http.StatusCreated, http.StatusNotFound}, true},
+		{"stale ifMatch", `{"operations":[{"op":"add","item":{"name":"Bread","amount":1}},{"op":"delete","name":"Milk","ifMatch":"\"7\""}]}`,
+			http.StatusPreconditionFailed, false, []int{http.StatusFailedDependency, http.StatusPreconditionFailed}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			w := serve(router, http.MethodPost, "/api/shoppingItems:batch", tt.body, nil)
+			var response handlers.BatchProblem
+			if err := json.Unmarshal(w.Body.Bytes(), &response); w.Code != tt.want || err != nil {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			// Failed batches are problem documents, like every other error response
+			wantType := "application/json; charset=utf-8"
+			if !tt.wantCommitted {
+				wantType = "application/problem+json"
+				if response.Status != tt.want || response.Code == "" || response.FailedOperation != len(tt.wantStatuses)-1 {
+					t.Errorf("problem %+v, want status %d, a code and failedOperation %d", response.Problem, tt.want, len(tt.wantStatuses)-1)
+				}
+			}
+			if ct := w.Header().Get("Content-Type"); ct != wantType {
+				t.Errorf("Content-Type %q, want %q", ct, wantType)
+			}
+			if response.Committed != tt.wantCommitted {
+				t.Errorf("committed = %t, want %t", response.Committed, tt.wantCommitted)
+			}
+			for i, result := range response.Results {
+				if result.Status != tt.wantStatuses[i] {
+					t.Errorf("operation %d: status %d, want %d", i, result.Status, tt.wantStatuses[i])
+				}
+			}
+			if got := serve(router, http.MethodGet, "/api/shoppingItems/Bread", "", nil).Code == http.StatusOK; got != tt.wantBread {
+				t.Errorf("Bread stored = %t, want %t", got, tt.wantBread)
+			}
+		})
+	}
+
+	router := newTestRouter(t)
+	for _, body := range []string{`{"operations":[]}`, `{"operations":[{"op":"rename"}]}`, `{"mode":"eventually","operations":[{"op":"delete","name":"Milk"}]}`} {
+		if w := serve(router, http.MethodPost, "/api/shoppingItems:batch", body, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: status %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -1,56 +1,114 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"shopping-api-backend-go/internal/models"
 	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ErrorResponse represents a standard error response
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
-// ResponseMessage represents a standard response message
-type ResponseMessage struct {
-	Message string `json:"message"`
-}
-
 // HealthCheck checks the health of the service
 // @Summary Health check
 // @Description Check the health status of the API
 // @Tags Health API
 // @Success 200 {string} string "API is up and running"
 // @Router /health [get]
-func HealthCheck(c *gin.Context) {
+func (h *Handler) HealthCheck(c *gin.Context) {
 	// Respond with a simple "API is up and running" message
 	c.JSON(http.StatusOK, gin.H{
 		"message": "API is up and running",
 	})
 }
 
-// GetItemByName retrieves an item by its name
-// @Summary Get a shopping item by name
-// @Description Retrieve a specific shopping item by its name
+// LiveCheck reports that the process is running and able to answer requests
+// @Summary Liveness probe
+// @Description Report that the process is running. It checks no dependencies, so a failing database doesn't get the pod restarted.
+// @Tags Health API
+// @Produce json
+// @Success 200 {object} models.HealthReport
+// @Router /livez [get]
+func (h *Handler) LiveCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthReport{Status: models.HealthOK, Checks: []models.CheckResult{}})
+}
+
+// ReadyCheck reports whether the server can take traffic
+// @Summary Readiness probe
+// @Description Check the database connection and the schema version, reporting each check with its latency. Fails while the server drains before a shutdown.
+// @Tags Health API
+// @Produce json
+// @Success 200 {object} models.HealthReport
+// @Failure 503 {object} models.HealthReport
+// @Router /readyz [get]
+func (h *Handler) ReadyCheck(c *gin.Context) {
+	if h.Readiness == nil {
+		h.LiveCheck(c)
+		return
+	}
+	report := h.Readiness.Report(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != models.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// RenameRequest is the request body for renaming a shopping item
+type RenameRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Oat milk"`
+}
+
+// resolveItem returns the list and item addressed by the request and writes a 404 if either doesn't exist.
+// List routes address items by ID; the /api/shoppingItems routes look them up by name in the default list.
+func (h *Handler) resolveItem(c *gin.Context) (string, models.ShoppingItem, bool) {
+	listID, ok := h.requireList(c)
+	if !ok {
+		return "", models.ShoppingItem{}, false
+	}
+
+	var item models.ShoppingItem
+	var err error
+	if id := c.Param("itemId"); id != "" {
+		item, err = h.Store.GetItem(c.Request.Context(), listID, id)
+	} else {
+		item, err = h.Store.GetItemByName(c.Request.Context(), listID, c.Param("name"))
+	}
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve item")
+		return "", models.ShoppingItem{}, false
+	}
+	return listID, item, true
+}
+
+// GetItem retrieves a shopping item by its ID, or by its name in the default list
+// @Summary Get a shopping item
+// @Description Retrieve a specific shopping item of the default list by its name
 // @Tags Shopping Items API
 // @Param name path string true "Item name"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is still current"
 // @Success 200 {object} models.ShoppingItem
-// @Failure 404 {object} ErrorResponse
+// @Success 304
+// @Header 200 {string} ETag "Version of the item"
+// @Failure 404 {object} models.Problem
 // @Router /api/shoppingItems/{name} [get]
-func GetItemByName(c *gin.Context) {
-	name := c.Param("name")
-
+func (h *Handler) GetItem(c *gin.Context) {
 	// Fetch item from the service layer
-	item, err := services.GetItemByName(services.DB(), name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, ErrorResponse{"Item not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{"Failed to retrieve item"})
-		}
+	_, item, ok := h.resolveItem(c)
+	if !ok {
+		return
+	}
+
+	// Skip the body if the client already has this version
+	c.Header("ETag", itemETag(item))
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, itemETag(item), true) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -58,55 +116,211 @@ func GetItemByName(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
-// UpdateItem updates a shopping item by its name
-// @Summary Update a shopping item by name
-// @Description Update a specific shopping item by its name
+// GetItemHistory retrieves the changes of a shopping item in the default list by its name
+// @Summary Get the history of a shopping item
+// @Description List every change of the items that have or had the name in the default list, oldest first,
+// @Description with the actor and time of the change and the item before and after it. Deleted items are included.
+// @Description Changes made before the history was introduced are not recorded.
+// @Tags Shopping Items API
+// @Param name path string true "Item name"
+// @Success 200 {array} models.ItemChange
+// @Failure 404 {object} models.Problem
+// @Router /api/shoppingItems/{name}/history [get]
+func (h *Handler) GetItemHistory(c *gin.Context) {
+	listID, ok := h.requireList(c)
+	if !ok {
+		return
+	}
+
+	// Fetch the changes from the service layer, which fails if no item ever had the name
+	changes, err := h.Store.GetItemHistory(c.Request.Context(), listID, c.Param("name"))
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve item history")
+		return
+	}
+
+	// Return the changes
+	c.JSON(http.StatusOK, changes)
+}
+
+// UpdateItem updates a shopping item
+// @Summary Update a shopping item
+// @Description Replace the amount and details of a shopping item. The name can only be changed through the rename endpoint.
 // @Tags Shopping Items API
 // @Param name path string true "Item name"
 // @Param shoppingItem body models.ShoppingItem true "Updated shopping item"
+// @Param If-Match header string false "Only update the item if it still has this ETag"
 // @Success 200 {object} models.ShoppingItem
-// @Failure 404 {object} ErrorResponse
+// @Header 200 {string} ETag "New version of the item"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
 // @Router /api/shoppingItems/{name} [put]
-func UpdateItem(c *gin.Context) {
-	name := c.Param("name")
-	var updatedItem models.ShoppingItem
-	if err := c.ShouldBindJSON(&updatedItem); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{"Invalid request payload"})
+func (h *Handler) UpdateItem(c *gin.Context) {
+	listID, item, ok := h.resolveItem(c)
+	if !ok {
 		return
 	}
 
-	// Input validation
-	if updatedItem.Amount <= 0 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{"Amount must be greater than zero"})
+	var updatedItem models.ShoppingItem
+	if !decodeJSON(c, &updatedItem) {
 		return
 	}
 
-	// Call the service layer to update the item
-	err := services.UpdateItem(services.DB(), name, updatedItem)
+	// Call the service layer to replace the item unless it changed since the client read it
+	updatedItem, err := replaceItem(c.Request.Context(), h.Store, listID, item, updatedItem, ifMatch(c), h.Now())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{"Failed to update item"})
+		h.respondWithServiceError(c, err, "Failed to update item")
 		return
 	}
 
 	// Return the updated item
+	c.Header("ETag", itemETag(updatedItem))
 	c.JSON(http.StatusOK, updatedItem)
 }
 
-// DeleteItem deletes a shopping item by its name
-// @Summary Delete a shopping item by name
-// @Description Delete a specific shopping item by its name
+// PatchItem partially updates a shopping item
+// @Summary Patch a shopping item
+// @Description Change some fields of a shopping item with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document.
+// @Description The patch is applied atomically and the result validated like a full update. The name can only be changed through the rename endpoint.
+// @Tags Shopping Items API
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Param name path string true "Item name"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "Only patch the item if it still has this ETag"
+// @Success 200 {object} models.ShoppingItem
+// @Header 200 {string} ETag "New version of the item"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Failure 415 {object} models.Problem
+// @Router /api/shoppingItems/{name} [patch]
+func (h *Handler) PatchItem(c *gin.Context) {
+	listID, item, ok := h.resolveItem(c)
+	if !ok {
+		return
+	}
+
+	mediaType := c.ContentType()
+	if mediaType != services.MergePatchType && mediaType != services.JSONPatchType {
+		utils.RespondWithError(c, http.StatusUnsupportedMediaType, "unsupported_media_type",
+			fmt.Sprintf("Content-Type must be %s or %s", services.MergePatchType, services.JSONPatchType))
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondWithInvalidPayload(c)
+		return
+	}
+
+	// Apply and validate the patch against the current item while the store holds it
+	check, now := ifMatch(c), h.Now()
+	patchedItem, err := h.Store.ModifyItem(c.Request.Context(), listID, item.ID, func(current *models.ShoppingItem) error {
+		if check != nil {
+			if err := check(*current); err != nil {
+				return err
+			}
+		}
+		previous := *current
+		if err := services.ApplyItemPatch(current, mediaType, patch); err != nil {
+			return err
+		}
+
+		current.Normalize()
+		var fields []models.FieldError
+		if current.ID != previous.ID {
+			fields = append(fields, fieldError("id", "immutable", "Item ID cannot be changed"))
+		}
+		if current.Name != previous.Name {
+			fields = append(fields, fieldError("name", "immutable", "Item name cannot be changed by an update, use the rename endpoint"))
+		}
+		current.ID, current.Name = previous.ID, previous.Name
+		fields, err := checkFields(current, fields...)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			return invalidFields(fields)
+		}
+		current.SyncCheckedAt(&previous, now)
+		return nil
+	})
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to patch item")
+		return
+	}
+
+	// Return the patched item
+	c.Header("ETag", itemETag(patchedItem))
+	c.JSON(http.StatusOK, patchedItem)
+}
+
+// RenameItem renames a shopping item
+// @Summary Rename a shopping item
+// @Description Change the name of a shopping item; its ID stays the same
+// @Tags Shopping Items API
+// @Param name path string true "Item name"
+// @Param rename body RenameRequest true "New item name"
+// @Success 200 {object} models.ShoppingItem
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/shoppingItems/{name}/rename [post]
+func (h *Handler) RenameItem(c *gin.Context) {
+	listID, item, ok := h.resolveItem(c)
+	if !ok {
+		return
+	}
+
+	var req RenameRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	// Input validation
+	req.Name = strings.TrimSpace(req.Name)
+	if !validate(c, &req) {
+		return
+	}
+
+	// Call the service layer to rename the item
+	err := h.Store.RenameItem(c.Request.Context(), listID, item.ID, req.Name)
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to rename item")
+		return
+	}
+
+	// Return the renamed item with its new version
+	if item, err = h.Store.GetItem(c.Request.Context(), listID, item.ID); err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve item")
+		return
+	}
+	c.Header("ETag", itemETag(item))
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItem deletes a shopping item
+// @Summary Delete a shopping item
+// @Description Delete a specific shopping item of the default list by its name
 // @Tags Shopping Items API
 // @Param name path string true "Item name"
-// @Success 200 {object} ResponseMessage
-// @Failure 404 {object} ErrorResponse
+// @Param If-Match header string false "Only delete the item if it still has this ETag"
+// @Success 204
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
 // @Router /api/shoppingItems/{name} [delete]
-func DeleteItem(c *gin.Context) {
-	name := c.Param("name")
+func (h *Handler) DeleteItem(c *gin.Context) {
+	listID, item, ok := h.resolveItem(c)
+	if !ok {
+		return
+	}
 
 	// Call the service layer to delete the item
-	err := services.DeleteItem(services.DB(), name)
+	err := h.Store.DeleteItem(c.Request.Context(), listID, item.ID, ifMatch(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{"Failed to delete item"})
+		h.respondWithServiceError(c, err, "Failed to delete item")
 		return
 	}
 
@@ -114,55 +328,306 @@ func DeleteItem(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// GetAllItems retrieves all shopping items
+// GetAllItems retrieves a page of the shopping items of a list
 // @Summary Get all shopping items
-// @Description Retrieve all shopping items
+// @Description Retrieve the shopping items of the default list, optionally filtered, sorted and paginated.
+// @Description The X-Total-Count header carries the number of matching items. When more items follow,
+// @Description X-Next-Cursor and a Link header with rel="next" point to the next page.
 // @Tags Shopping Items API
+// @Param name query string false "Only return the item with this name"
+// @Param namePrefix query string false "Only return items whose name starts with this prefix"
+// @Param unit query string false "Only return items with this unit"
+// @Param category query string false "Only return items in this category"
+// @Param checked query bool false "Only return checked or unchecked items"
+// @Param minAmount query int false "Only return items with at least this amount"
+// @Param maxAmount query int false "Only return items with at most this amount"
+// @Param sort query string false "Sort by name or amount, prefix with - for descending order" default(name)
+// @Param limit query int false "Page size" default(100) maximum(1000)
+// @Param cursor query string false "Cursor from the X-Next-Cursor header of the previous page"
+// @Param asOf query string false "Return the items as they were at this RFC 3339 time, reconstructed from their history" format(date-time)
 // @Success 200 {array} models.ShoppingItem
+// @Header 200 {integer} X-Total-Count "Number of items matching the filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
 // @Router /api/shoppingItems [get]
-func GetAllItems(c *gin.Context) {
-	items, err := services.GetAllItems(services.DB())
+func (h *Handler) GetAllItems(c *gin.Context) {
+	listID, ok := h.requireList(c)
+	if !ok {
+		return
+	}
+
+	query, fields := parseItemQuery(c)
+	if len(fields) > 0 {
+		utils.RespondWithValidationError(c, fields)
+		return
+	}
+
+	page, err := h.Store.GetAllItems(c.Request.Context(), listID, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{"Failed to retrieve items"})
+		h.respondWithServiceError(c, err, "Failed to retrieve items")
 		return
 	}
 
-	// Return all items
-	c.JSON(http.StatusOK, items)
+	// Paging metadata goes into headers so the body stays a plain array
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		next := *c.Request.URL
+		params := next.Query()
+		params.Set("cursor", page.NextCursor)
+		next.RawQuery = params.Encode()
+		c.Header("X-Next-Cursor", page.NextCursor)
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	// Return the page of items
+	c.JSON(http.StatusOK, page.Items)
 }
 
-// AddItem adds a new shopping item to the list
+// parseItemQuery builds the filter, sort and page of GetAllItems from the query parameters
+// and returns the parameters that are invalid
+func parseItemQuery(c *gin.Context) (services.ItemQuery, []models.FieldError) {
+	var fields []models.FieldError
+	query := services.ItemQuery{
+		ItemFilter: services.ItemFilter{
+			Name:       c.Query("name"),
+			NamePrefix: c.Query("namePrefix"),
+			Unit:       c.Query("unit"),
+			Category:   c.Query("category"),
+		},
+	}
+
+	if raw, ok := c.GetQuery("checked"); ok {
+		if checked, err := strconv.ParseBool(raw); err != nil {
+			fields = append(fields, fieldError("checked", "boolean", "checked must be true or false"))
+		} else {
+			query.Checked = &checked
+		}
+	}
+	for param, dest := range map[string]**int{"minAmount": &query.MinAmount, "maxAmount": &query.MaxAmount} {
+		if raw, ok := c.GetQuery(param); ok {
+			amount, err := strconv.Atoi(raw)
+			if err != nil {
+				fields = append(fields, fieldError(param, "integer", param+" must be an integer"))
+				continue
+			}
+			*dest = &amount
+		}
+	}
+
+	var err error
+	if query.Sort, err = services.ParseItemSort(c.Query("sort")); err != nil {
+		fields = append(fields, fieldError("sort", services.ErrorCode(err), err.Error()))
+	}
+	if raw, ok := c.GetQuery("limit"); ok {
+		query.Limit, err = strconv.Atoi(raw)
+		if err != nil || query.Limit < 1 || query.Limit > services.MaxPageSize {
+			fields = append(fields, fieldError("limit", "range", fmt.Sprintf("limit must be between 1 and %d", services.MaxPageSize)))
+		}
+	}
+	if raw, ok := c.GetQuery("asOf"); ok {
+		asOf, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			fields = append(fields, fieldError("asOf", "timestamp", "asOf must be an RFC 3339 time such as 2025-05-01T09:30:00Z"))
+		} else {
+			query.AsOf = &asOf
+		}
+	}
+	if raw := c.Query("cursor"); raw != "" && len(fields) == 0 {
+		if query.After, err = services.DecodeCursor(raw, query.Sort); err != nil {
+			fields = append(fields, fieldError("cursor", services.ErrorCode(err), err.Error()))
+		}
+	}
+	return query, fields
+}
+
+// AddItem adds a new shopping item to a list
 // @Summary Add a new shopping item
-// @Description Add a new item to the shopping list
+// @Description Add a new item to the default list. The item ID is generated by the server.
+// @Description With upsert=true an item with the same name is merged instead: the amounts are added up,
+// @Description the unit, category, notes and price that are set and the checked state are taken over.
 // @Tags Shopping Items API
 // @Param shoppingItem body models.ShoppingItem true "New shopping item"
+// @Param upsert query bool false "Merge into an existing item with the same name instead of failing"
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries of this request return the original response"
+// @Success 200 {object} models.ShoppingItem "Merged into an existing item"
 // @Success 201 {object} models.ShoppingItem
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
 // @Router /api/shoppingItems [post]
-func AddItem(c *gin.Context) {
+func (h *Handler) AddItem(c *gin.Context) {
+	listID, ok := h.requireList(c)
+	if !ok {
+		return
+	}
+
+	upsert := false
+	if raw, ok := c.GetQuery("upsert"); ok {
+		var err error
+		if upsert, err = strconv.ParseBool(raw); err != nil {
+			utils.RespondWithValidationError(c, []models.FieldError{fieldError("upsert", "boolean", "upsert must be true or false")})
+			return
+		}
+	}
+
 	var newItem models.ShoppingItem
-	if err := c.ShouldBindJSON(&newItem); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{"Invalid request payload"})
+	if !decodeJSON(c, &newItem) {
 		return
 	}
 
-	// Input validation
-	if newItem.Name == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{"Item name cannot be empty"})
+	// Call the service layer to validate and add or merge the item
+	status := http.StatusCreated
+	var err error
+	if upsert {
+		var created bool
+		newItem, created, err = upsertItem(c.Request.Context(), h.Store, listID, newItem, h.Now())
+		if !created {
+			status = http.StatusOK
+		}
+	} else {
+		newItem, err = addItem(c.Request.Context(), h.Store, listID, newItem, h.Now())
+	}
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to add item")
 		return
 	}
-	if newItem.Amount <= 0 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{"Amount must be greater than zero"})
+
+	// Return the newly added or merged item
+	c.Header("ETag", itemETag(newItem))
+	c.JSON(status, newItem)
+}
+
+// AdjustAmountRequest is the request body for incrementing or decrementing the amount of a shopping item.
+// Both fields are optional; the body may be left out entirely.
+type AdjustAmountRequest struct {
+	By     int                 `json:"by,omitempty" binding:"min=1,max=10000" example:"1"`
+	AtZero services.ZeroPolicy `json:"atZero,omitempty" binding:"oneof=delete clamp" example:"delete"`
+}
+
+// IncrementItem atomically increases the amount of a shopping item
+// @Summary Increment the amount of a shopping item
+// @Description Atomically add to the amount of a shopping item, by 1 unless the body says otherwise.
+// @Description The amount cannot exceed 10000.
+// @Tags Shopping Items API
+// @Param name path string true "Item name"
+// @Param adjust body AdjustAmountRequest false "Amount to add"
+// @Success 200 {object} models.ShoppingItem
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/shoppingItems/{name}/increment [post]
+func (h *Handler) IncrementItem(c *gin.Context) {
+	h.adjustItemAmount(c, 1)
+}
+
+// DecrementItem atomically decreases the amount of a shopping item
+// @Summary Decrement the amount of a shopping item
+// @Description Atomically subtract from the amount of a shopping item, by 1 unless the body says otherwise.
+// @Description When the amount reaches zero the item is deleted and 204 is returned, unless atZero is clamp,
+// @Description which keeps the item with an amount of zero.
+// @Tags Shopping Items API
+// @Param name path string true "Item name"
+// @Param adjust body AdjustAmountRequest false "Amount to subtract and what to do at zero"
+// @Success 200 {object} models.ShoppingItem
+// @Success 204
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /api/shoppingItems/{name}/decrement [post]
+func (h *Handler) DecrementItem(c *gin.Context) {
+	h.adjustItemAmount(c, -1)
+}
+
+// adjustItemAmount changes the amount of the addressed item in the direction of sign
+func (h *Handler) adjustItemAmount(c *gin.Context, sign int) {
+	listID, item, ok := h.resolveItem(c)
+	if !ok {
 		return
 	}
 
-	// Call the service layer to add the item
-	err := services.AddItem(services.DB(), newItem)
+	req := AdjustAmountRequest{By: 1, AtZero: services.DeleteAtZero}
+	if c.Request.ContentLength != 0 {
+		if !decodeJSON(c, &req) {
+			return
+		}
+		if !validate(c, &req) {
+			return
+		}
+	}
+
+	// Call the service layer to change the amount atomically
+	item, deleted, err := h.Store.AdjustItemAmount(c.Request.Context(), listID, item.ID, sign*req.By, req.AtZero)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{"Failed to add item"})
+		h.respondWithServiceError(c, err, "Failed to adjust item amount")
 		return
 	}
 
-	// Return the newly added item
-	c.JSON(http.StatusCreated, newItem)
+	// Return the changed item, or 204 No Content if it was deleted
+	if deleted {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Header("ETag", itemETag(item))
+	c.JSON(http.StatusOK, item)
+}
+
+// addItem validates a new item and adds it to a list of the store.
+// Invalid items are rejected with an invalidFields error.
+func addItem(ctx context.Context, store services.ItemStore, listID string, item models.ShoppingItem, now time.Time) (models.ShoppingItem, error) {
+	item.Normalize()
+	fields, err := checkFields(&item)
+	if err != nil {
+		return models.ShoppingItem{}, err
+	}
+	if len(fields) > 0 {
+		return models.ShoppingItem{}, invalidFields(fields)
+	}
+
+	item.SyncCheckedAt(nil, now)
+	return store.AddItem(ctx, listID, item)
+}
+
+// upsertItem validates an item and adds it to a list of the store or merges it into the item with the same name.
+// Invalid items are rejected with an invalidFields error.
+func upsertItem(ctx context.Context, store services.ItemStore, listID string, item models.ShoppingItem, now time.Time) (models.ShoppingItem, bool, error) {
+	item.Normalize()
+	fields, err := checkFields(&item)
+	if err != nil {
+		return models.ShoppingItem{}, false, err
+	}
+	if len(fields) > 0 {
+		return models.ShoppingItem{}, false, invalidFields(fields)
+	}
+
+	item.SyncCheckedAt(nil, now)
+	return store.UpsertItem(ctx, listID, item)
+}
+
+// replaceItem validates the new state of an item and writes it to the store if the stored item passes check.
+// The name may be left out but cannot change. Invalid items are rejected with an invalidFields error.
+func replaceItem(ctx context.Context, store services.ItemStore, listID string, item, update models.ShoppingItem, check services.ItemCheck, now time.Time) (models.ShoppingItem, error) {
+	update.Normalize()
+	var fields []models.FieldError
+	if update.Name != "" && update.Name != item.Name {
+		fields = append(fields, fieldError("name", "immutable", "Item name cannot be changed by an update, use the rename endpoint"))
+	}
+	update.ID, update.Name = item.ID, item.Name
+	fields, err := checkFields(&update, fields...)
+	if err != nil {
+		return models.ShoppingItem{}, err
+	}
+	if len(fields) > 0 {
+		return models.ShoppingItem{}, invalidFields(fields)
+	}
+
+	return store.ModifyItem(ctx, listID, item.ID, func(current *models.ShoppingItem) error {
+		if check != nil {
+			if err := check(*current); err != nil {
+				return err
+			}
+		}
+		update.SyncCheckedAt(current, now)
+		*current = update
+		return nil
+	})
 }
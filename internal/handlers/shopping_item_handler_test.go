@@ -0,0 +1,310 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter returns the router serving a fresh MemoryStore holding Milk
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := services.NewMemoryStore()
+	if _, err := store.AddItem(context.Background(), services.DefaultListID, models.ShoppingItem{Name: "Milk", Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	h := handlers.New(store, handlers.Config{})
+	h.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	return web.InitializeRouter(h)
+}
+
+// serve sends a request with the given headers to the router and returns the response
+func serve(router http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestItemLifecycle(t *testing.T) {
+	router := newTestRouter(t)
+	steps := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodGet, "/api/shoppingItems/Milk", "", http.StatusOK},
+		{http.MethodPost, "/api/shoppingItems", `{"name":"Bread","amount":2}`, http.StatusCreated},
+		{http.MethodPut, "/api/shoppingItems/Bread", `{"name":"Bread","amount":3}`, http.StatusOK},
+		{http.MethodPut, "/api/shoppingItems/Bread", `{"name":"Toast","amount":3}`, http.StatusBadRequest}, // names change through rename
+		{http.MethodPost, "/api/shoppingItems/Bread/rename", `{"name":"Milk"}`, http.StatusConflict},
+		{http.MethodDelete, "/api/shoppingItems/Milk", "", http.StatusNoContent},
+		{http.MethodGet, "/api/shoppingItems/Milk", "", http.StatusNotFound},
+		{http.MethodDelete, "/api/shoppingItems/Milk", "", http.StatusNotFound},
+		{http.MethodPut, "/api/shoppingItems/Milk", `{"name":"Milk","amount":3}`, http.StatusNotFound},
+		{http.MethodPost, "/api/shoppingItems", `{"name":"Bread","amount":1}`, http.StatusConflict},
+	}
+	for i, step := range steps {
+		if w := serve(router, step.method, step.path, step.body, nil); w.Code != step.want {
+			t.Fatalf("step %d, %s %s: status %d, want %d: %s", i, step.method, step.path, w.Code, step.want, w.Body)
+		}
+	}
+
+	w := serve(router, http.MethodGet, "/api/shoppingItems", "", nil)
+	var items []models.ShoppingItem
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "Bread" || items[0].Amount != 3 || items[0].ID == "" {
+		t.Errorf("GET /api/shoppingItems = %+v, want Bread with amount 3 and an ID", items)
+	}
+}
+
+func TestAddItemValidation(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"name":"Eggs","amount":6}`, http.StatusCreated},
+		{`{"name":"","amount":6}`, http.StatusBadRequest},
+		{`{"name":"Eggs","amount":0}`, http.StatusBadRequest},
+		{`{"name":"Eggs",`, http.StatusBadRequest},
+		{`{"name":"Eggs","amount":6,"unit":"box","category":"dairy","price":1.29}`, http.StatusCreated},
+		{`{"name":"Eggs","amount":6,"price":-1}`, http.StatusBadRequest},
+		{`{"name":"Eggs","amount":6,"price":1.299}`, http.StatusBadRequest}, // the database keeps cents
+		{`{"name":"Eggs","amount":6,"unit":"` + strings.Repeat("x", 21) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		router := newTestRouter(t)
+		if w := serve(router, http.MethodPost, "/api/shoppingItems", tt.body, nil); w.Code != tt.want {
+			t.Errorf("POST %s: status %d, want %d: %s", tt.body, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+func TestCheckItem(t *testing.T) {
+	router := newTestRouter(t)
+	update := func(body string) models.ShoppingItem {
+		t.Helper()
+		w := serve(router, http.MethodPut, "/api/shoppingItems/Milk", body, nil)
+		var item models.ShoppingItem
+		if err := json.Unmarshal(w.Body.Bytes(), &item); w.Code != http.StatusOK || err != nil {
+			t.Fatalf("PUT %s: status %d: %s", body, w.Code, w.Body)
+		}
+		return item
+	}
+
+	checked := update(`{"amount":1,"checked":true}`)
+	if checked.CheckedAt == nil {
+		t.Fatal("checking the item didn't set checkedAt")
+	}
+	if again := update(`{"amount":2,"checked":true}`); again.CheckedAt == nil || !again.CheckedAt.Equal(*checked.CheckedAt) {
+		t.Errorf("checkedAt = %v after another update, want %v", again.CheckedAt, checked.CheckedAt)
+	}
+	if unchecked := update(`{"amount":2,"checked":false}`); unchecked.CheckedAt != nil {
+		t.Errorf("checkedAt = %v after unchecking, want none", unchecked.CheckedAt)
+	}
+
+	w := serve(router, http.MethodGet, "/api/shoppingItems?checked=maybe", "", nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GET with checked=maybe: status %d, want 400", w.Code)
+	}
+}
+
+// TestGetAllItemsPaging follows the Link headers through the pages of the default list
+func TestGetAllItemsPaging(t *testing.T) {
+	router := newTestRouter(t)
+	for _, name := range []string{"Apples", "Bread", "Cheese"} {
+		if w := serve(router, http.MethodPost, "/api/shoppingItems", `{"name":"`+name+`","amount":1}`, nil); w.Code != http.StatusCreated {
+			t.Fatalf("adding %s: status %d: %s", name, w.Code, w.Body)
+		}
+	}
+
+	var got []string
+	path := "/api/shoppingItems?sort=-name&limit=3"
+	for path != "" {
+		w := serve(router, http.MethodGet, path, "", nil)
+		var items []models.ShoppingItem
+		if err := json.Unmarshal(w.Body.Bytes(), &items); w.Code != http.StatusOK || err != nil {
+			t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body)
+		}
+		if total := w.Header().Get("X-Total-Count"); total != "4" {
+			t.Errorf("GET %s: X-Total-Count %q, want 4", path, total)
+		}
+		for _, item := range items {
+			got = append(got, item.Name)
+		}
+		path = ""
+		if link := w.Header().Get("Link"); link != "" {
+			path = strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`)
+		}
+	}
+	if want := []string{"Milk", "Cheese", "Bread", "Apples"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+
+	for _, query := range []string{"limit=0", "limit=1001", "sort=price", "cursor=nonsense", "minAmount=many"} {
+		if w := serve(router, http.MethodGet, "/api/shoppingItems?"+query, "", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("GET with %s: status %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestPatchItem(t *testing.T) {
+	const (
+		mergePatch = "application/merge-patch+json"
+		jsonPatch  = "application/json-patch+json"
+	)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+		wantAmount  int
+	}{
+		{"merge patch", mergePatch, `{"amount":4,"unit":"l"}`, http.StatusOK, 4},
+		{"merge patch removing a field", mergePatch, `{"unit":null}`, http.StatusOK, 1},
+		{"JSON Patch", jsonPatch, `[{"op":"test","path":"/amount","value":1},{"op":"replace","path":"/amount","value":3}]`, http.StatusOK, 3},
+		{"failed test op", jsonPatch, `[{"op":"test","path":"/amount","value":5},{"op":"replace","path":"/amount","value":3}]`, http.StatusConflict, 1},
+		{"invalid result", mergePatch, `{"amount":0}`, http.StatusBadRequest, 1},
+		{"renaming", mergePatch, `{"name":"Oat milk"}`, http.StatusBadRequest, 1},
+		{"malformed JSON Patch", jsonPatch, `{"op":"replace"}`, http.StatusBadRequest, 1},
+		{"plain JSON", "application/json", `{"amount":4}`, http.StatusUnsupportedMediaType, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			w := serve(router, http.MethodPatch, "/api/shoppingItems/Milk", tt.body, map[string]string{"Content-Type": tt.contentType})
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+
+			// A rejected patch must leave the item alone
+			w = serve(router, http.MethodGet, "/api/shoppingItems/Milk", "", nil)
+			var item models.ShoppingItem
+			if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil {
+				t.Fatal(err)
+			}
+			if item.Amount != tt.wantAmount {
+				t.Errorf("amount = %d after the patch, want %d", item.Amount, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestUpsertItem(t *testing.T) {
+	router := newTestRouter(t)
+	w := serve(router, http.MethodPost, "/api/shoppingItems?upsert=true", `{"name":"Milk","amount":2,"unit":"l"}`, nil)
+	var item models.ShoppingItem
+	if err := json.Unmarshal(w.Body.Bytes(), &item); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("upserting Milk: status %d, want 200: %s", w.Code, w.Body)
+	}
+	if item.Amount != 3 || item.Unit != "l" {
+		t.Errorf("upserted Milk = %+v, want amount 3 in l", item)
+	}
+	if w := serve(router, http.MethodPost, "/api/shoppingItems?upsert=true", `{"name":"Bread","amount":1}`, nil); w.Code != http.StatusCreated {
+		t.Errorf("upserting Bread: status %d, want 201: %s", w.Code, w.Body)
+	}
+	if w := serve(router, http.MethodPost, "/api/shoppingItems?upsert=true", `{"name":"Milk","amount":9998}`, nil); w.Code != http.StatusConflict {
+		t.Errorf("upserting Milk past 10000: status %d, want 409: %s", w.Code, w.Body)
+	}
+}
+
+func TestAdjustItemAmount(t *testing.T) {
+	type step struct {
+		action string
+		body   string
+		want   int
+		amount int // of Milk afterwards, -1 if deleted
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"increment", []step{
+			{"increment", "", http.StatusOK, 2},
+			{"increment", `{"by":3}`, http.StatusOK, 5},
+			{"increment", `{"by":9996}`, http.StatusConflict, 5}, // past 10000
+			{"increment", `{"by":0}`, http.StatusBadRequest, 5},
+		}},
+		{"delete at zero", []step{
+			{"increment", "", http.StatusOK, 2},
+			{"decrement", "", http.StatusOK, 1},
+			{"decrement", `{"atZero":"delete"}`, http.StatusNoContent, -1},
+			{"decrement", "", http.StatusNotFound, -1},
+		}},
+		{"delete below zero", []step{
+			{"decrement", `{"by":5}`, http.StatusNoContent, -1},
+		}},
+		{"clamp at zero", []step{
+			{"decrement", `{"by":5,"atZero":"clamp"}`, http.StatusOK, 0},
+			{"decrement", `{"atZero":"clamp"}`, http.StatusOK, 0},
+			{"increment", "", http.StatusOK, 1},
+		}},
+		{"unknown policy", []step{
+			{"decrement", `{"atZero":"ignore"}`, http.StatusBadRequest, 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t)
+			for i, s := range tt.steps {
+				w := serve(router, http.MethodPost, "/api/shoppingItems/Milk/"+s.action, s.body, nil)
+				if w.Code != s.want {
+					t.Fatalf("step %d, %s %s: status %d, want %d: %s", i, s.action, s.body, w.Code, s.want, w.Body)
+				}
+				w = serve(router, http.MethodGet, "/api/shoppingItems/Milk", "", nil)
+				var item models.ShoppingItem
+				json.Unmarshal(w.Body.Bytes(), &item)
+				if got := map[bool]int{true: item.Amount, false: -1}[w.Code == http.StatusOK]; got != s.amount {
+					t.Errorf("step %d, %s %s: amount %d afterwards, want %d", i, s.action, s.body, got, s.amount)
+				}
+			}
+		})
+	}
+}
+
+// TestUpdateClampedItem checks that only a clamped decrement leaves an amount of 0 behind:
+// updates of the clamped item still need an amount of at least 1
+func TestUpdateClampedItem(t *testing.T) {
+	router := newTestRouter(t)
+	if w := serve(router, http.MethodPost, "/api/shoppingItems/Milk/decrement", `{"atZero":"clamp"}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("clamping Milk: status %d: %s", w.Code, w.Body)
+	}
+	patch := map[string]string{"Content-Type": "application/merge-patch+json"}
+	steps := []struct {
+		method string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{http.MethodPut, `{"amount":0,"notes":"none left"}`, nil, http.StatusBadRequest},
+		{http.MethodPatch, `{"notes":"none left"}`, patch, http.StatusBadRequest},
+		{http.MethodPatch, `{"amount":2,"notes":"buy two"}`, patch, http.StatusOK},
+	}
+	for i, step := range steps {
+		if w := serve(router, step.method, "/api/shoppingItems/Milk", step.body, step.header); w.Code != step.want {
+			t.Errorf("step %d, %s %s: status %d, want %d: %s", i, step.method, step.body, w.Code, step.want, w.Body)
+		}
+	}
+}
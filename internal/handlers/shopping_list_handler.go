@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"shopping-api-backend-go/internal/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListRequest is the request body for creating or renaming a shopping list
+type ListRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"Household"`
+}
+
+// requireList returns the list addressed by the request and writes a 404 if it doesn't exist.
+// The /api/shoppingItems routes carry no list ID and use the default list.
+func (h *Handler) requireList(c *gin.Context) (string, bool) {
+	listID := c.Param("listId")
+	if listID == "" {
+		return services.DefaultListID, true
+	}
+
+	if _, err := h.Store.GetList(c.Request.Context(), listID); err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve list")
+		return "", false
+	}
+	return listID, true
+}
+
+// GetAllLists retrieves all shopping lists
+// @Summary Get all shopping lists
+// @Description Retrieve all shopping lists, including the default list
+// @Tags Shopping Lists API
+// @Success 200 {array} models.ShoppingList
+// @Router /api/lists [get]
+func (h *Handler) GetAllLists(c *gin.Context) {
+	lists, err := h.Store.GetAllLists(c.Request.Context())
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve lists")
+		return
+	}
+
+	// Return all lists
+	c.JSON(http.StatusOK, lists)
+}
+
+// GetList retrieves a shopping list by its ID
+// @Summary Get a shopping list by ID
+// @Description Retrieve a specific shopping list by its ID
+// @Tags Shopping Lists API
+// @Param listId path string true "List ID"
+// @Success 200 {object} models.ShoppingList
+// @Failure 404 {object} models.Problem
+// @Router /api/lists/{listId} [get]
+func (h *Handler) GetList(c *gin.Context) {
+	list, err := h.Store.GetList(c.Request.Context(), c.Param("listId"))
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to retrieve list")
+		return
+	}
+
+	// Return the shopping list
+	c.JSON(http.StatusOK, list)
+}
+
+// CreateList creates a new shopping list
+// @Summary Create a shopping list
+// @Description Create a new, empty shopping list with a generated ID
+// @Tags Shopping Lists API
+// @Param list body ListRequest true "New shopping list"
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries of this request return the original response"
+// @Success 201 {object} models.ShoppingList
+// @Failure 400 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists [post]
+func (h *Handler) CreateList(c *gin.Context) {
+	var req ListRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	// Input validation
+	req.Name = strings.TrimSpace(req.Name)
+	if !validate(c, &req) {
+		return
+	}
+
+	// Call the service layer to create the list
+	list, err := h.Store.CreateList(c.Request.Context(), req.Name)
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to create list")
+		return
+	}
+
+	// Return the newly created list
+	c.JSON(http.StatusCreated, list)
+}
+
+// RenameList renames a shopping list
+// @Summary Rename a shopping list
+// @Description Change the name of a shopping list; its ID and items stay the same
+// @Tags Shopping Lists API
+// @Param listId path string true "List ID"
+// @Param list body ListRequest true "New list name"
+// @Success 200 {object} models.ShoppingList
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists/{listId} [put]
+func (h *Handler) RenameList(c *gin.Context) {
+	listID := c.Param("listId")
+	var req ListRequest
+	if !decodeJSON(c, &req) {
+		return
+	}
+
+	// Input validation
+	req.Name = strings.TrimSpace(req.Name)
+	if !validate(c, &req) {
+		return
+	}
+
+	// Call the service layer to rename the list
+	if err := h.Store.RenameList(c.Request.Context(), listID, req.Name); err != nil {
+		h.respondWithServiceError(c, err, "Failed to rename list")
+		return
+	}
+
+	// Return the renamed list
+	h.GetList(c)
+}
+
+// DeleteList deletes a shopping list and all of its items
+// @Summary Delete a shopping list
+// @Description Delete a shopping list and all of its items. The default list cannot be deleted.
+// @Tags Shopping Lists API
+// @Param listId path string true "List ID"
+// @Success 204
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists/{listId} [delete]
+func (h *Handler) DeleteList(c *gin.Context) {
+	// Call the service layer to delete the list
+	err := h.Store.DeleteList(c.Request.Context(), c.Param("listId"))
+	if err != nil {
+		h.respondWithServiceError(c, err, "Failed to delete list")
+		return
+	}
+
+	// Return 204 No Content after successful deletion
+	c.Status(http.StatusNoContent)
+}
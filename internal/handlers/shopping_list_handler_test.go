@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"shopping-api-backend-go/internal/models"
+)
+
+func TestListItems(t *testing.T) {
+	router := newTestRouter(t)
+	w := serve(router, http.MethodPost, "/api/lists", `{"name":" Household "}`, nil)
+	var list models.ShoppingList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); w.Code != http.StatusCreated || err != nil {
+		t.Fatalf("POST /api/lists: status %d: %s", w.Code, w.Body)
+	}
+	if list.Name != "Household" || list.ID == "" {
+		t.Errorf("created list %+v, want the trimmed name and an ID", list)
+	}
+
+	w = serve(router, http.MethodPost, "/api/lists/"+list.ID+"/items", `{"name":"Soap","amount":2}`, nil)
+	var soap models.ShoppingItem
+	if err := json.Unmarshal(w.Body.Bytes(), &soap); w.Code != http.StatusCreated || err != nil {
+		t.Fatalf("POST /api/lists/%s/items: status %d: %s", list.ID, w.Code, w.Body)
+	}
+	item := "/api/lists/" + list.ID + "/items/" + soap.ID
+
+	steps := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodPost, item + "/rename", `{"name":"Hand soap"}`, http.StatusOK},
+		{http.MethodGet, item, "", http.StatusOK},
+		{http.MethodGet, "/api/shoppingItems/Hand%20soap", "", http.StatusNotFound},        // not in the default list
+		{http.MethodGet, "/api/lists/" + list.ID + "/items/Soap", "", http.StatusNotFound}, // items are addressed by ID
+		{http.MethodGet, "/api/lists/missing/items", "", http.StatusNotFound},
+		{http.MethodPost, "/api/lists", `{"name":"  "}`, http.StatusBadRequest},
+		{http.MethodDelete, "/api/lists/default", "", http.StatusConflict},
+		{http.MethodDelete, "/api/lists/" + list.ID, "", http.StatusNoContent},
+		{http.MethodGet, item, "", http.StatusNotFound},
+	}
+	for i, step := range steps {
+		if w := serve(router, step.method, step.path, step.body, nil); w.Code != step.want {
+			t.Errorf("step %d, %s %s: status %d, want %d: %s", i, step.method, step.path, w.Code, step.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// The routes under /api/lists/{listId}/items share their handlers with the /api/shoppingItems routes,
+// which address items by name in the default list. Each gets its own doc block here, so the API
+// documentation only lists the path parameters of its own route.
+
+// GetAllListItems retrieves a page of the shopping items of a list
+// @Summary Get all shopping items of a list
+// @Description Retrieve the shopping items of a list, optionally filtered, sorted and paginated.
+// @Description The X-Total-Count header carries the number of matching items. When more items follow,
+// @Description X-Next-Cursor and a Link header with rel="next" point to the next page.
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param name query string false "Only return the item with this name"
+// @Param namePrefix query string false "Only return items whose name starts with this prefix"
+// @Param unit query string false "Only return items with this unit"
+// @Param category query string false "Only return items in this category"
+// @Param checked query bool false "Only return checked or unchecked items"
+// @Param minAmount query int false "Only return items with at least this amount"
+// @Param maxAmount query int false "Only return items with at most this amount"
+// @Param sort query string false "Sort by name or amount, prefix with - for descending order" default(name)
+// @Param limit query int false "Page size" default(100) maximum(1000)
+// @Param cursor query string false "Cursor from the X-Next-Cursor header of the previous page"
+// @Param asOf query string false "Return the items as they were at this RFC 3339 time, reconstructed from their history" format(date-time)
+// @Success 200 {array} models.ShoppingItem
+// @Header 200 {integer} X-Total-Count "Number of items matching the filters"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /api/lists/{listId}/items [get]
+func (h *Handler) GetAllListItems(c *gin.Context) {
+	h.GetAllItems(c)
+}
+
+// AddListItem adds a new shopping item to a list
+// @Summary Add a new shopping item to a list
+// @Description Add a new item to a shopping list. The item ID is generated by the server.
+// @Description With upsert=true an item with the same name is merged instead: the amounts are added up,
+// @Description the unit, category, notes and price that are set and the checked state are taken over.
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param shoppingItem body models.ShoppingItem true "New shopping item"
+// @Param upsert query bool false "Merge into an existing item with the same name instead of failing"
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries of this request return the original response"
+// @Success 200 {object} models.ShoppingItem "Merged into an existing item"
+// @Success 201 {object} models.ShoppingItem
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists/{listId}/items [post]
+func (h *Handler) AddListItem(c *gin.Context) {
+	h.AddItem(c)
+}
+
+// GetListItem retrieves a shopping item of a list by its ID
+// @Summary Get a shopping item of a list
+// @Description Retrieve a specific shopping item of a list by its ID
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is still current"
+// @Success 200 {object} models.ShoppingItem
+// @Success 304
+// @Header 200 {string} ETag "Version of the item"
+// @Failure 404 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId} [get]
+func (h *Handler) GetListItem(c *gin.Context) {
+	h.GetItem(c)
+}
+
+// UpdateListItem updates a shopping item of a list
+// @Summary Update a shopping item of a list
+// @Description Replace the amount and details of a shopping item. The name can only be changed through the rename endpoint.
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param shoppingItem body models.ShoppingItem true "Updated shopping item"
+// @Param If-Match header string false "Only update the item if it still has this ETag"
+// @Success 200 {object} models.ShoppingItem
+// @Header 200 {string} ETag "New version of the item"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId} [put]
+func (h *Handler) UpdateListItem(c *gin.Context) {
+	h.UpdateItem(c)
+}
+
+// PatchListItem partially updates a shopping item of a list
+// @Summary Patch a shopping item of a list
+// @Description Change some fields of a shopping item with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document.
+// @Description The patch is applied atomically and the result validated like a full update. The name can only be changed through the rename endpoint.
+// @Tags Shopping Items API
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "Only patch the item if it still has this ETag"
+// @Success 200 {object} models.ShoppingItem
+// @Header 200 {string} ETag "New version of the item"
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Failure 415 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId} [patch]
+func (h *Handler) PatchListItem(c *gin.Context) {
+	h.PatchItem(c)
+}
+
+// RenameListItem renames a shopping item of a list
+// @Summary Rename a shopping item of a list
+// @Description Change the name of a shopping item; its ID stays the same
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param rename body RenameRequest true "New item name"
+// @Success 200 {object} models.ShoppingItem
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId}/rename [post]
+func (h *Handler) RenameListItem(c *gin.Context) {
+	h.RenameItem(c)
+}
+
+// DeleteListItem deletes a shopping item of a list
+// @Summary Delete a shopping item of a list
+// @Description Delete a specific shopping item of a list by its ID
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param If-Match header string false "Only delete the item if it still has this ETag"
+// @Success 204
+// @Failure 404 {object} models.Problem
+// @Failure 412 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId} [delete]
+func (h *Handler) DeleteListItem(c *gin.Context) {
+	h.DeleteItem(c)
+}
+
+// IncrementListItem atomically increases the amount of a shopping item of a list
+// @Summary Increment the amount of a shopping item of a list
+// @Description Atomically add to the amount of a shopping item, by 1 unless the body says otherwise.
+// @Description The amount cannot exceed 10000.
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param adjust body AdjustAmountRequest false "Amount to add"
+// @Success 200 {object} models.ShoppingItem
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Failure 409 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId}/increment [post]
+func (h *Handler) IncrementListItem(c *gin.Context) {
+	h.IncrementItem(c)
+}
+
+// DecrementListItem atomically decreases the amount of a shopping item of a list
+// @Summary Decrement the amount of a shopping item of a list
+// @Description Atomically subtract from the amount of a shopping item, by 1 unless the body says otherwise.
+// @Description When the amount reaches zero the item is deleted and 204 is returned, unless atZero is clamp,
+// @Description which keeps the item with an amount of zero.
+// @Tags Shopping Items API
+// @Param listId path string true "List ID"
+// @Param itemId path string true "Item ID"
+// @Param adjust body AdjustAmountRequest false "Amount to subtract and what to do at zero"
+// @Success 200 {object} models.ShoppingItem
+// @Success 204
+// @Failure 400 {object} models.Problem
+// @Failure 404 {object} models.Problem
+// @Router /api/lists/{listId}/items/{itemId}/decrement [post]
+func (h *Handler) DecrementListItem(c *gin.Context) {
+	h.DecrementItem(c)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report fields by their JSON names so they match the request body
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+		// decimals=n limits the decimals of a number, such as the cents of a price
+		v.RegisterValidation("decimals", func(fl validator.FieldLevel) bool {
+			n, err := strconv.Atoi(fl.Param())
+			return err == nil && models.HasDecimals(fl.Field().Float(), n)
+		})
+	}
+}
+
+// decodeJSON decodes the request body into obj without validating it and writes a 400 if it is malformed.
+// Callers normalize the value and then pass it to validate.
+func decodeJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.Body == nil || json.NewDecoder(c.Request.Body).Decode(obj) != nil {
+		respondWithInvalidPayload(c)
+		return false
+	}
+	return true
+}
+
+// validate checks obj against its binding tags and writes a 400 listing the invalid fields,
+// together with any fields the caller already found invalid
+func validate(c *gin.Context, obj interface{}, fields ...models.FieldError) bool {
+	fields, err := checkFields(obj, fields...)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, "internal_error", "Failed to validate request")
+		return false
+	}
+	if len(fields) > 0 {
+		utils.RespondWithValidationError(c, fields)
+		return false
+	}
+	return true
+}
+
+// checkFields appends the fields of obj that break its binding tags to fields
+func checkFields(obj interface{}, fields ...models.FieldError) ([]models.FieldError, error) {
+	err := binding.Validator.ValidateStruct(obj)
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		for _, fe := range invalid {
+			fields = append(fields, fieldError(fieldPath(fe), fe.Tag(), validationMessage(fe)))
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// invalidFields is returned from store callbacks to abort a change that failed validation
+type invalidFields []models.FieldError
+
+// Error summarizes the invalid fields
+func (f invalidFields) Error() string {
+	return fmt.Sprintf("%d invalid fields", len(f))
+}
+
+// fieldPath returns the JSON path of an invalid field, such as "operations[2].op", without the struct name
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+// validationMessage describes a failed validation rule in words
+func validationMessage(fe validator.FieldError) string {
+	unit := ""
+	switch fe.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice:
+		unit = " entries"
+	}
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s cannot be empty", fe.Field())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", fe.Field(), fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", fe.Field(), fe.Param(), unit)
+	case "decimals":
+		return fmt.Sprintf("%s can have at most %s decimals", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	}
+	return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+}
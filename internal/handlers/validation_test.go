@@ -0,0 +1,59 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"shopping-api-backend-go/internal/models"
+)
+
+// TestFieldErrors checks the field, rule and message reported for each invalid field of a new item
+func TestFieldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []models.FieldError
+	}{
+		{"valid", `{"name":" Eggs ","amount":6,"price":1.29}`, nil},
+		{"blank name", `{"name":"   ","amount":1}`, []models.FieldError{
+			{Field: "name", Code: "required", Message: "name cannot be empty"},
+		}},
+		{"amount range", `{"name":"Eggs","amount":10001}`, []models.FieldError{
+			{Field: "amount", Code: "max", Message: "amount must be at most 10000"},
+		}},
+		{"every rule", `{"name":"` + strings.Repeat("x", 101) + `","amount":0,"unit":"` + strings.Repeat("x", 21) + `","price":1.299}`, []models.FieldError{
+			{Field: "name", Code: "max", Message: "name must be at most 100 characters"},
+			{Field: "amount", Code: "min", Message: "amount must be at least 1"},
+			{Field: "unit", Code: "max", Message: "unit must be at most 20 characters"},
+			{Field: "price", Code: "decimals", Message: "price can have at most 2 decimals"},
+		}},
+		{"negative price", `{"name":"Eggs","amount":1,"price":-0.5}`, []models.FieldError{
+			{Field: "price", Code: "min", Message: "price must be at least 0"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(newTestRouter(t), http.MethodPost, "/api/shoppingItems", tt.body, nil)
+			if tt.want == nil {
+				if w.Code != http.StatusCreated {
+					t.Fatalf("status %d, want 201: %s", w.Code, w.Body)
+				}
+				var item models.ShoppingItem
+				if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil || item.Name != "Eggs" {
+					t.Errorf("added %+v, %v; want the trimmed name Eggs", item, err)
+				}
+				return
+			}
+			var problem models.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); w.Code != http.StatusBadRequest || err != nil {
+				t.Fatalf("status %d, want 400: %s", w.Code, w.Body)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.want) {
+				t.Errorf("errors = %+v, want %+v", problem.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package health decides whether the server is ready to take traffic
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	dbmigrate "shopping-api-backend-go/db"
+	"shopping-api-backend-go/internal/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check is a dependency the server needs to serve requests; Run fails while it is unavailable
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Readiness runs the checks of the server and tracks whether it is draining before a shutdown
+type Readiness struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewReadiness returns a Readiness running checks, each cut short after timeout
+func NewReadiness(timeout time.Duration, checks ...Check) *Readiness {
+	return &Readiness{checks: checks, timeout: timeout}
+}
+
+// Drain marks the server as shutting down. From then on Report fails without running the checks,
+// so load balancers stop sending new requests while the running ones finish.
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// Report runs all checks concurrently and reports each one with its latency.
+// The server is ready if every check passes and it isn't draining.
+func (r *Readiness) Report(ctx context.Context) models.HealthReport {
+	if r.draining.Load() {
+		return models.HealthReport{Status: models.HealthDraining, Checks: []models.CheckResult{}}
+	}
+
+	report := models.HealthReport{Status: models.HealthOK, Checks: make([]models.CheckResult, len(r.checks))}
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != models.HealthOK {
+			report.Status = models.HealthFailing
+		}
+	}
+	return report
+}
+
+// run runs one check under the timeout
+func (r *Readiness) run(ctx context.Context, check Check) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := models.CheckResult{
+		Name:      check.Name,
+		Status:    models.HealthOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = models.HealthFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Database checks that the database answers a ping
+func Database(db *sql.DB) Check {
+	return Check{Name: "database", Run: db.PingContext}
+}
+
+// Migrations checks that every embedded migration has been applied to the database.
+// A schema ahead of the binary passes, so old pods keep serving during a rolling update.
+// The migrations are read once here; each run only queries the version of the database.
+func Migrations(db *sql.DB, dialect string) (Check, error) {
+	provider, err := dbmigrate.NewProvider(db, dialect)
+	if err != nil {
+		return Check{}, err
+	}
+	var latest int64
+	if sources := provider.ListSources(); len(sources) > 0 {
+		latest = sources[len(sources)-1].Version
+	}
+
+	return Check{Name: "migrations", Run: func(ctx context.Context) error {
+		current, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if current < latest {
+			return fmt.Errorf("database schema is at version %d, expected %d", current, latest)
+		}
+		return nil
+	}}, nil
+}
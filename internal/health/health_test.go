@@ -0,0 +1,38 @@
+package health_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	dbmigrate "shopping-api-backend-go/db"
+	"shopping-api-backend-go/internal/health"
+	"shopping-api-backend-go/internal/services"
+
+	"github.com/pressly/goose/v3"
+)
+
+// TestMigrations checks that the migrations check fails until the schema is up to date
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	conn, err := services.OpenSQLite(services.SQLiteScheme + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	check, err := health.Migrations(conn, dbmigrate.DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := check.Run(ctx); err == nil {
+		t.Error("Run passed on an empty database")
+	}
+	goose.SetLogger(goose.NopLogger())
+	if err := dbmigrate.RunMigrations(conn, dbmigrate.DialectSQLite); err != nil {
+		t.Fatal(err)
+	}
+	if err := check.Run(ctx); err != nil {
+		t.Errorf("Run failed after migrating: %v", err)
+	}
+}
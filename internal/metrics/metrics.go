@@ -0,0 +1,132 @@
+// Package metrics collects the Prometheus metrics of the server: HTTP requests,
+// database operations, the connection pool and the contents of the store
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"shopping-api-backend-go/internal/services"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of the metrics of the server
+const namespace = "shopping_api"
+
+// queryBuckets are the latency buckets of database operations in seconds; most finish in well under 5ms
+var queryBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+// Metrics holds the collectors of the server in a registry of its own,
+// so several servers in one process keep their metrics apart
+type Metrics struct {
+	registry *prometheus.Registry
+	logger   *slog.Logger
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	queries  *prometheus.HistogramVec
+}
+
+// New returns Metrics with the HTTP and query collectors and the Go runtime and process metrics registered.
+// Errors while gathering are logged to logger.
+func New(logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   logger,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests handled, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_operation_duration_seconds",
+			Help:      "Time taken by the database operations of the store, by operation.",
+			Buckets:   queryBuckets,
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.latency, m.queries,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format. A collector that fails
+// is logged and left out instead of failing the whole scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(m.logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ObserveRequest records a handled HTTP request. Route is the route template, such as
+// /api/lists/:listId, so the number of series doesn't grow with the number of items.
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.latency.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// ObserveQuery records a finished database operation; it is a services.QueryObserver
+func (m *Metrics) ObserveQuery(operation string, elapsed time.Duration) {
+	m.queries.WithLabelValues(operation).Observe(elapsed.Seconds())
+}
+
+// RegisterDB exports the connection pool statistics of db as go_sql_* metrics labelled with name
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterStore exports the number of shopping lists and items in store, counted on every scrape
+func (m *Metrics) RegisterStore(store services.ItemStore) {
+	m.registry.MustRegister(&storeCollector{store: store})
+}
+
+// Descriptions of the store gauges
+var (
+	itemsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "items"),
+		"Number of shopping items in all lists.", nil, nil)
+	listsDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "lists"),
+		"Number of shopping lists.", nil, nil)
+)
+
+// storeCollector counts the contents of a store when the metrics are gathered
+type storeCollector struct {
+	store services.ItemStore
+}
+
+// Describe sends the descriptions of the store gauges
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- itemsDesc
+	ch <- listsDesc
+}
+
+// Collect counts the items and lists. The store's query timeout bounds the queries;
+// a failed count is reported as an invalid metric.
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	if count, err := c.store.CountItems(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(itemsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(itemsDesc, prometheus.GaugeValue, float64(count))
+	}
+	if lists, err := c.store.GetAllLists(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(listsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(listsDesc, prometheus.GaugeValue, float64(len(lists)))
+	}
+}
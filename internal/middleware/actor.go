@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"shopping-api-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Actor attributes the request to the client of its X-API-Key header if that is one of apiKeys,
+// or else to the client address. Unknown keys are ignored, so a client can't pose as another
+// by sending a made-up key. The actor is carried by the request context, so the stores record
+// it with every item change the request makes.
+func Actor(apiKeys []string) gin.HandlerFunc {
+	known := knownAPIKeys(apiKeys)
+	return func(c *gin.Context) {
+		actor := "ip:" + c.ClientIP()
+		if id := verifiedAPIKey(c, known); id != "" {
+			actor = "key:" + id
+		}
+		c.Request = c.Request.WithContext(services.WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
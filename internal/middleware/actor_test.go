@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shopping-api-backend-go/internal/middleware"
+	"shopping-api-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		want   string // prefix of the actor
+	}{
+		{"no key", "", "ip:192.0.2.1"},
+		{"known key", "known", "key:"},
+		{"unknown key", "made-up", "ip:192.0.2.1"},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.Actor([]string{"known"}))
+			var actor string
+			r.GET("/items", func(c *gin.Context) {
+				actor = services.ActorFrom(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tt.apiKey != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.apiKey)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			if !strings.HasPrefix(actor, tt.want) {
+				t.Errorf("actor %q, want %s...", actor, tt.want)
+			}
+			if strings.Contains(actor, tt.apiKey) && tt.apiKey != "" {
+				t.Errorf("actor %q shows the API key", actor)
+			}
+		})
+	}
+}
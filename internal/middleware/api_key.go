@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader names the request header carrying the API key of a client
+const APIKeyHeader = "X-API-Key"
+
+// knownAPIKeys indexes the API keys the server was configured with by their apiKeyID
+func knownAPIKeys(keys []string) map[string]bool {
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[apiKeyID(key)] = true
+	}
+	return known
+}
+
+// verifiedAPIKey returns the apiKeyID of the X-API-Key header of the request if it is one of known,
+// or "" if the header is missing or holds a key the server doesn't know
+func verifiedAPIKey(c *gin.Context, known map[string]bool) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		if id := apiKeyID(key); known[id] {
+			return id
+		}
+	}
+	return ""
+}
+
+// apiKeyID identifies an API key by a hash, so the key itself isn't stored or shown
+func apiKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/pkg/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader names the request header carrying a client-chosen idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests that carry an Idempotency-Key header safe to retry.
+// The first request with a key runs normally and its response is kept for ttl. Retries with the same key
+// and payload get that response replayed; reusing the key for a different payload is rejected.
+// Responses with a 5xx status are not kept, so the request can be retried with the same key.
+// Keys are scoped to the client that sent them, see idempotencyScope; apiKeys are the known API keys.
+// Failures to keep track of keys are logged to logger.
+func Idempotency(store services.IdempotencyStore, ttl time.Duration, apiKeys []string, logger *slog.Logger) gin.HandlerFunc {
+	known := knownAPIKeys(apiKeys)
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid_idempotency_key", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		// Read the body so it can be hashed and still handed to the handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusBadRequest, "invalid_payload", "Invalid request payload")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		scoped := idempotencyScope(c, known) + " " + key
+		record := services.IdempotencyRecord{Key: scoped, RequestHash: requestHash(c.Request, body), ExpiresAt: now.Add(ttl)}
+		existing, err := store.ReserveIdempotencyKey(c.Request.Context(), record, now)
+		switch {
+		case errors.Is(err, services.ErrAlreadyExists):
+			replay(c, record, existing)
+			return
+		case errors.Is(err, services.ErrTimeout):
+			utils.RespondWithError(c, http.StatusGatewayTimeout, services.ErrorCode(err), err.Error())
+			return
+		case errors.Is(err, services.ErrUnavailable):
+			utils.RespondWithError(c, http.StatusServiceUnavailable, services.ErrorCode(err), err.Error())
+			return
+		case err != nil:
+			logger.ErrorContext(c.Request.Context(), "failed to reserve idempotency key", "error", err)
+			utils.RespondWithError(c, http.StatusInternalServerError, "internal_error", "Failed to process idempotency key")
+			return
+		}
+
+		// Run the request and keep its response for retries. A panicking handler frees the key again.
+		// The bookkeeping must happen even if the client has gone away, so it ignores cancellation.
+		ctx := context.WithoutCancel(c.Request.Context())
+		defer func() {
+			if p := recover(); p != nil {
+				store.ReleaseIdempotencyKey(ctx, scoped)
+				panic(p)
+			}
+		}()
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if status := writer.Status(); status >= http.StatusInternalServerError {
+			err = store.ReleaseIdempotencyKey(ctx, scoped)
+		} else {
+			record.StatusCode, record.Header, record.Body = status, writer.Header().Clone(), writer.body.Bytes()
+			err = store.CompleteIdempotencyKey(ctx, record)
+		}
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "failed to store idempotency key", "error", err)
+		}
+	}
+}
+
+// idempotencyScope names the client whose keys a request shares. Clients with a known API key get a scope
+// of their own. Everyone else shares one scope and has to pick keys that are unique, such as UUIDs: their
+// address can't tell them apart, since a phone retrying a request may have moved from wifi to cellular.
+func idempotencyScope(c *gin.Context, known map[string]bool) string {
+	if id := verifiedAPIKey(c, known); id != "" {
+		return "key:" + id
+	}
+	return "anonymous"
+}
+
+// replay answers a retry with the response kept for its key
+func replay(c *gin.Context, record, existing services.IdempotencyRecord) {
+	switch {
+	case existing.RequestHash != record.RequestHash:
+		utils.RespondWithError(c, http.StatusUnprocessableEntity, "idempotency_key_reused",
+			"Idempotency-Key was already used for a different request")
+	case existing.StatusCode == 0:
+		utils.RespondWithError(c, http.StatusConflict, "idempotency_key_in_progress",
+			"A request with this Idempotency-Key is still being processed")
+	default:
+		// The replay keeps the ID of the retry, so both requests can be told apart in the logs
+		for name, values := range existing.Header {
+			if name != http.CanonicalHeaderKey(RequestIDHeader) {
+				c.Writer.Header()[name] = values
+			}
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Writer.WriteHeader(existing.StatusCode)
+		c.Writer.Write(existing.Body)
+		c.Abort()
+	}
+}
+
+// requestHash fingerprints the method, target and body of a request
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isMutating reports whether requests with the method change data
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// recordingWriter copies the response body while writing it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes and records part of the response body
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes and records part of the response body
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,136 @@
+package middleware_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/middleware"
+	"shopping-api-backend-go/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentRouter answers POST /items with 201 and the number of requests the handler has run,
+// or with 503 while fail is set. Requests for /slow wait until release is closed.
+type idempotentRouter struct {
+	*gin.Engine
+	calls   int
+	fail    bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func newIdempotentRouter() *idempotentRouter {
+	gin.SetMode(gin.TestMode)
+	r := &idempotentRouter{Engine: gin.New(), started: make(chan struct{}), release: make(chan struct{})}
+	r.Use(middleware.Idempotency(services.NewMemoryStore(), time.Hour, []string{"key-one", "key-two"}, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	r.POST("/items", func(c *gin.Context) {
+		if r.fail {
+			c.Status(http.StatusServiceUnavailable)
+			return
+		}
+		r.calls++
+		c.JSON(http.StatusCreated, gin.H{"calls": r.calls})
+	})
+	r.POST("/slow", func(c *gin.Context) {
+		close(r.started)
+		<-r.release
+		c.Status(http.StatusCreated)
+	})
+	return r
+}
+
+// post sends body to path with the given Idempotency-Key and X-API-Key headers
+func (r *idempotentRouter) post(path, body, key, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	if apiKey != "" {
+		req.Header.Set(middleware.APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	r := newIdempotentRouter()
+	first := r.post("/items", `{"name":"Milk"}`, "k1", "")
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first request: status %d, headers %v", first.Code, first.Header())
+	}
+
+	retry := r.post("/items", `{"name":"Milk"}`, "k1", "")
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry: status %d, body %s, headers %v; want the replayed first response %s", retry.Code, retry.Body, retry.Header(), first.Body)
+	}
+	if r.calls != 1 {
+		t.Errorf("handler ran %d times, want once", r.calls)
+	}
+
+	// A different payload can't reuse the key, whether the body or the target differs
+	for _, path := range []string{"/items", "/items?upsert=true"} {
+		if w := r.post(path, `{"name":"Bread"}`, "k1", ""); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "idempotency_key_reused") {
+			t.Errorf("reusing the key for POST %s: status %d, body %s; want 422 idempotency_key_reused", path, w.Code, w.Body)
+		}
+	}
+	if w := r.post("/items", `{}`, strings.Repeat("k", 256), ""); w.Code != http.StatusBadRequest {
+		t.Errorf("256 character key: status %d, want 400", w.Code)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	r := newIdempotentRouter()
+	done := make(chan int)
+	go func() { done <- r.post("/slow", "", "k1", "").Code }()
+	<-r.started
+
+	if w := r.post("/slow", "", "k1", ""); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "idempotency_key_in_progress") {
+		t.Errorf("retry while running: status %d, body %s; want 409 idempotency_key_in_progress", w.Code, w.Body)
+	}
+	close(r.release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("first request: status %d, want 201", code)
+	}
+	if w := r.post("/slow", "", "k1", ""); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry after it finished: status %d, headers %v; want a replayed 201", w.Code, w.Header())
+	}
+}
+
+func TestIdempotencyServerError(t *testing.T) {
+	r := newIdempotentRouter()
+	r.fail = true
+	if w := r.post("/items", `{}`, "k1", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("failing request: status %d, want 503", w.Code)
+	}
+	r.fail = false
+	if w := r.post("/items", `{}`, "k1", ""); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry after a 5xx: status %d, headers %v; want the request to run again", w.Code, w.Header())
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	r := newIdempotentRouter()
+	r.post("/items", `{}`, "k1", "key-one")
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		replayed bool
+	}{
+		{"same API key", "key-one", true},
+		{"other API key", "key-two", false},
+		{"no API key", "", false},
+		{"unknown API key", "made-up", true}, // shares the scope of clients without a key
+	}
+	for _, tt := range tests {
+		w := r.post("/items", `{}`, "k1", tt.apiKey)
+		if replayed := w.Header().Get("Idempotent-Replayed") == "true"; w.Code != http.StatusCreated || replayed != tt.replayed {
+			t.Errorf("%s: status %d, replayed %t; want 201, replayed %t", tt.name, w.Code, replayed, tt.replayed)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"shopping-api-backend-go/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of every request by method, route template and status
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveRequest(c.Request.Method, route(c), c.Writer.Status(), time.Since(start))
+	}
+}
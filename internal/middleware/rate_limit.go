@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limit keys, choosing whose requests share a bucket
+const (
+	RateLimitByIP     = "ip"      // the client address
+	RateLimitByAPIKey = "api_key" // a known X-API-Key header, or the client address without one
+)
+
+// RateLimitConfig holds the rate limiting policies of the API
+type RateLimitConfig struct {
+	Key   string             // one of the RateLimitBy constants
+	Read  services.RateLimit // requests that change nothing, such as GET
+	Write services.RateLimit // POST, PUT, PATCH and DELETE requests
+}
+
+// RateLimit limits the requests to the /api routes with a token bucket per client and kind of request.
+// Every response carries RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers;
+// requests over the limit are rejected with 429 Too Many Requests and a Retry-After header.
+// With RateLimitByAPIKey, clients sending one of apiKeys are limited by key.
+// If the store fails, the failure is logged to logger and the request is let through.
+func RateLimit(store services.RateLimitStore, config RateLimitConfig, apiKeys []string, logger *slog.Logger) gin.HandlerFunc {
+	known := map[string]bool{}
+	if config.Key == RateLimitByAPIKey {
+		known = knownAPIKeys(apiKeys)
+	}
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			c.Next()
+			return
+		}
+		kind, limit := "write", config.Write
+		if !isMutating(c.Request.Method) {
+			kind, limit = "read", config.Read
+		}
+		if limit.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		result, err := store.TakeRateLimitToken(c.Request.Context(), kind+":"+clientKey(c, known), limit, time.Now())
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "failed to check rate limit, letting the request through", "error", err)
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Limit, ceilSeconds(limit.Window)))
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			header.Set("Retry-After", strconv.Itoa(retryAfter))
+			utils.RespondWithError(c, http.StatusTooManyRequests, "rate_limited",
+				fmt.Sprintf("Too many %s requests, retry in %d seconds", kind, retryAfter))
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey identifies the client of a request for rate limiting by its API key if that is one of known,
+// hashed so it isn't kept in the rate limit store, or else by its address. Unknown keys are ignored,
+// so a client can't get a fresh bucket by sending a new key with every request.
+func clientKey(c *gin.Context, known map[string]bool) string {
+	if id := verifiedAPIKey(c, known); id != "" {
+		return "key:" + id
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as the rate limit headers expect
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/middleware"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/web"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRateLimit sends reads from several clients to a router allowing 2 reads per minute
+// and checks whose requests share a bucket
+func TestRateLimit(t *testing.T) {
+	type request struct {
+		addr   string
+		apiKey string
+		want   int
+	}
+	tests := []struct {
+		name     string
+		key      string
+		requests []request
+	}{
+		{"by address", middleware.RateLimitByIP, []request{
+			{"192.0.2.1", "", http.StatusOK},
+			{"192.0.2.1", "a", http.StatusOK},
+			{"192.0.2.1", "b", http.StatusTooManyRequests},
+			{"192.0.2.2", "", http.StatusOK},
+		}},
+		{"by known API key", middleware.RateLimitByAPIKey, []request{
+			{"192.0.2.1", "known", http.StatusOK},
+			{"192.0.2.2", "known", http.StatusOK},
+			{"192.0.2.3", "known", http.StatusTooManyRequests}, // the key is limited wherever it comes from
+			{"192.0.2.1", "", http.StatusOK},
+		}},
+		{"made-up API keys", middleware.RateLimitByAPIKey, []request{
+			{"192.0.2.1", "a", http.StatusOK},
+			{"192.0.2.1", "b", http.StatusOK},
+			{"192.0.2.1", "c", http.StatusTooManyRequests}, // unknown keys share the bucket of the address
+			{"192.0.2.1", "known", http.StatusOK},
+		}},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handlers.New(services.NewMemoryStore(), handlers.Config{
+				APIKeys: []string{"known"},
+				RateLimit: middleware.RateLimitConfig{
+					Key:  tt.key,
+					Read: services.RateLimit{Limit: 2, Window: time.Minute},
+				},
+			})
+			h.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+			h.RateLimiter = services.NewMemoryRateLimiter()
+			router := web.InitializeRouter(h)
+
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodGet, "/api/shoppingItems", nil)
+				req.RemoteAddr = r.addr + ":1234"
+				if r.apiKey != "" {
+					req.Header.Set(middleware.APIKeyHeader, r.apiKey)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != r.want {
+					t.Errorf("request %d from %s with key %q: status %d, want %d", i, r.addr, r.apiKey, w.Code, r.want)
+				}
+				if w.Header().Get("RateLimit-Policy") != "2;w=60" {
+					t.Errorf("request %d: RateLimit-Policy %q, want 2;w=60", i, w.Header().Get("RateLimit-Policy"))
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "30" {
+					t.Errorf("request %d: Retry-After %q, want 30", i, w.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shopping-api-backend-go/internal/logging"
+	"shopping-api-backend-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool // whether the ID of the client is taken over
+	}{
+		{"generated", "", false},
+		{"propagated", "client-id-42", true},
+		{"too long", strings.Repeat("x", 129), false},
+		{"control characters", "id\nforged log line", false},
+		{"spaces", "two words", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			var logs bytes.Buffer
+			r := gin.New()
+			r.Use(middleware.RequestID(), middleware.AccessLog(logging.New(&logs, slog.LevelInfo)))
+			var seen string // the request ID the handler finds in its context
+			r.GET("/items", func(c *gin.Context) {
+				seen = logging.RequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(middleware.RequestIDHeader)
+			if tt.keep && id != tt.header {
+				t.Errorf("X-Request-ID = %q, want the client's %q", id, tt.header)
+			}
+			if !tt.keep {
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("X-Request-ID = %q, want a generated UUID", id)
+				}
+			}
+			if seen != id {
+				t.Errorf("request context carries %q, want %q", seen, id)
+			}
+
+			var record struct {
+				Msg       string `json:"msg"`
+				RequestID string `json:"request_id"`
+				Route     string `json:"route"`
+				Status    int    `json:"status"`
+			}
+			if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+				t.Fatalf("access log %q: %v", logs.String(), err)
+			}
+			if record.Msg != "request" || record.RequestID != id || record.Route != "/items" || record.Status != http.StatusOK {
+				t.Errorf("access log = %+v, want the request to /items with ID %s", record, id)
+			}
+		})
+	}
+}
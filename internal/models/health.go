@@ -0,0 +1,22 @@
+package models
+
+// Health statuses
+const (
+	HealthOK       = "ok"
+	HealthFailing  = "failing"
+	HealthDraining = "draining" // the server is about to shut down and takes no new traffic
+)
+
+// HealthReport is the response of the liveness and readiness probes
+type HealthReport struct {
+	Status string        `json:"status" example:"ok"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Name      string  `json:"name" example:"database"`
+	Status    string  `json:"status" example:"ok"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty" example:"context deadline exceeded"`
+}
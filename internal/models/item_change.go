@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ItemChangeOp names the kind of change recorded in the history of an item
+type ItemChangeOp string
+
+// Item change operations
+const (
+	ItemCreated ItemChangeOp = "create"
+	ItemUpdated ItemChangeOp = "update"
+	ItemRenamed ItemChangeOp = "rename"
+	ItemDeleted ItemChangeOp = "delete"
+)
+
+// ItemChange is one entry in the history of a shopping item: who changed it when, and how it looked
+// before and after. Before is absent for creations and After for deletions.
+type ItemChange struct {
+	ID     int64         `json:"id" example:"42"`
+	ItemID string        `json:"itemId" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Op     ItemChangeOp  `json:"op" enums:"create,update,rename,delete" example:"update"`
+	Actor  string        `json:"actor" example:"ip:203.0.113.7"`
+	At     time.Time     `json:"at" example:"2025-05-01T09:30:00Z"`
+	Before *ShoppingItem `json:"before,omitempty"`
+	After  *ShoppingItem `json:"after,omitempty"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// Problem is an RFC 7807 problem details error response, sent as application/problem+json
+type Problem struct {
+	Type     string       `json:"type" example:"about:blank"`
+	Title    string       `json:"title" example:"Not Found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty" example:"item not found"`
+	Instance string       `json:"instance,omitempty" example:"/api/shoppingItems/Milk"`
+	Code     string       `json:"code" example:"item_not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single field of a request failed validation
+type FieldError struct {
+	Field   string `json:"field" example:"amount"`
+	Code    string `json:"code" example:"min"`
+	Message string `json:"message" example:"Amount must be greater than zero"`
+}
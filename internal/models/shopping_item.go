@@ -1,17 +1,53 @@
 package models
 
-// ShoppingItem represents a shopping item with a name and amount
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// ShoppingItem represents a shopping item with a name and amount.
+// The ID is generated by the server when the item is added.
+// Version starts at 1 and is incremented by the server on every change; it is ignored in request bodies.
+// The binding tags hold the validation rules of every endpoint that writes items. Only a clamped
+// decrement, which bypasses them, can leave an item with an amount of 0.
 type ShoppingItem struct {
-	Name   string `json:"name" example:"Milk"`
-	Amount int    `json:"amount" example:"2"`
+	ID        string     `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Name      string     `json:"name" binding:"required,max=100" example:"Milk"`
+	Amount    int        `json:"amount" binding:"min=1,max=10000" example:"2"`
+	Unit      string     `json:"unit,omitempty" binding:"max=20" example:"l"`
+	Category  string     `json:"category,omitempty" binding:"max=50" example:"dairy"`
+	Notes     string     `json:"notes,omitempty" binding:"max=500" example:"Lactose free"`
+	Price     *float64   `json:"price,omitempty" binding:"omitempty,min=0,max=99999999,decimals=2" example:"1.29"`
+	Checked   bool       `json:"checked" example:"false"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty" example:"2025-04-01T12:00:00Z"`
+	Version   int        `json:"version" example:"1"`
+}
+
+// Normalize trims surrounding whitespace from the text fields before they are validated
+func (i *ShoppingItem) Normalize() {
+	i.Name = strings.TrimSpace(i.Name)
+	i.Unit = strings.TrimSpace(i.Unit)
+	i.Category = strings.TrimSpace(i.Category)
+	i.Notes = strings.TrimSpace(i.Notes)
 }
 
-// ErrorResponse represents a standard error response
-type ErrorResponse struct {
-	Error string `json:"error"`
+// HasDecimals reports whether value has no more than n decimals. The decimals binding tag uses it
+// to reject prices that the database would round to cents.
+func HasDecimals(value float64, n int) bool {
+	scaled := value * math.Pow10(n)
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6 // floats can't hold most decimals exactly
 }
 
-// ResponseMessage represents a standard response message
-type ResponseMessage struct {
-	Message string `json:"message"`
+// SyncCheckedAt keeps CheckedAt in step with Checked. It is set to now when the item
+// becomes checked, carried over from previous while it stays checked, and cleared otherwise.
+func (i *ShoppingItem) SyncCheckedAt(previous *ShoppingItem, now time.Time) {
+	switch {
+	case !i.Checked:
+		i.CheckedAt = nil
+	case previous != nil && previous.Checked:
+		i.CheckedAt = previous.CheckedAt
+	default:
+		i.CheckedAt = &now
+	}
 }
@@ -0,0 +1,7 @@
+package models
+
+// ShoppingList represents a named list that groups shopping items
+type ShoppingList struct {
+	ID   string `json:"id" example:"3f2b9c1e-8d4a-4c57-9a0e-6b1f2d3c4e5f"`
+	Name string `json:"name" example:"Household"`
+}
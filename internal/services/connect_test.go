@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/services"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+// servePostgres answers the startup and the simple queries of one client with the least the PostgreSQL
+// protocol allows, which is enough for lib/pq to ping
+func servePostgres(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	readyForQuery := []byte{'Z', 0, 0, 0, 5, 'I'}
+
+	// The startup message has no type byte, only its length
+	var length int32
+	if binary.Read(r, binary.BigEndian, &length) != nil || readBody(r, length) != nil {
+		return
+	}
+	conn.Write(append([]byte{'R', 0, 0, 0, 8, 0, 0, 0, 0}, readyForQuery...)) // AuthenticationOk
+
+	for {
+		msgType, err := r.ReadByte()
+		if err != nil || binary.Read(r, binary.BigEndian, &length) != nil || readBody(r, length) != nil {
+			return
+		}
+		switch msgType {
+		case 'Q':
+			conn.Write(append([]byte{'I', 0, 0, 0, 4}, readyForQuery...)) // EmptyQueryResponse
+		case 'X':
+			return
+		}
+	}
+}
+
+// readBody discards the rest of a message whose length includes the length field itself
+func readBody(r io.Reader, length int32) error {
+	_, err := io.CopyN(io.Discard, r, int64(length)-4)
+	return err
+}
+
+// freePort returns a local port nothing listens on
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+	return port
+}
+
+// TestInitDBRetries checks that InitDB waits for a database that starts after the server
+func TestInitDBRetries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	port := freePort(t)
+	go func() {
+		time.Sleep(time.Second)
+		l, err := net.Listen("tcp", "127.0.0.1:"+port)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		go func() {
+			<-ctx.Done()
+			l.Close()
+		}()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go servePostgres(conn)
+		}
+	}()
+
+	db, err := services.InitDB(ctx, fmt.Sprintf("host=127.0.0.1 port=%s user=test dbname=test sslmode=disable", port), services.PoolConfig{})
+	if err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	db.Close()
+}
+
+// TestInitDBGivesUp checks that InitDB stops retrying when ctx ends and reports the last connection error
+func TestInitDBGivesUp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	_, err := services.InitDB(ctx, "host=127.0.0.1 port="+freePort(t)+" user=test dbname=test sslmode=disable", services.PoolConfig{})
+	if err == nil {
+		t.Fatal("InitDB connected to a closed port")
+	}
+	if !strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "after 1 attempts") {
+		t.Errorf("got %q, want the connection error after several attempts", err)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Kinds of service errors. Stores return them wrapped in an *Error; check them with errors.Is.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrValidation    = errors.New("validation failed")
+	ErrConflict      = errors.New("conflict")
+	// ErrPreconditionFailed means a conditional write found the item at a different version
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrTimeout means the database did not answer within the query timeout
+	ErrTimeout = errors.New("timeout")
+	// ErrUnavailable means the request was cancelled, because the client went away or the server is shutting down
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error is a service error of one of the kinds above with a message that is safe to show to clients
+type Error struct {
+	Kind    error
+	Code    string // machine-readable code such as "item_not_found"
+	Message string
+	Err     error // underlying database error, if any
+}
+
+// Error returns the client-facing message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is the kind of this error
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying database error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError returns an *Error of the given kind and code with a formatted message
+func newError(kind error, code, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorCode returns the machine-readable code of a service error, or "" for other errors
+func ErrorCode(err error) string {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code
+	}
+	return ""
+}
+
+// dbError translates database errors into service errors; subject names the affected
+// resource in the message, for example "item". Other errors are returned unchanged.
+func dbError(err error, subject string) error {
+	if err == nil {
+		return nil
+	}
+	code := strings.ReplaceAll(subject, " ", "_")
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Code: code + "_not_found", Message: subject + " not found", Err: err}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return &Error{Kind: ErrAlreadyExists, Code: code + "_already_exists", Message: subject + " already exists", Err: err}
+		case "23503": // foreign_key_violation
+			return &Error{Kind: ErrNotFound, Code: "list_not_found", Message: "list not found", Err: err}
+		case "23502", "23514", "22001": // not_null_violation, check_violation, string_data_right_truncation
+			return &Error{Kind: ErrValidation, Code: "invalid_" + code, Message: "invalid " + subject, Err: err}
+		case "40001": // serialization_failure
+			return &Error{Kind: ErrConflict, Code: "concurrent_modification", Message: subject + " was modified concurrently", Err: err}
+		}
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return &Error{Kind: ErrAlreadyExists, Code: code + "_already_exists", Message: subject + " already exists", Err: err}
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return &Error{Kind: ErrNotFound, Code: "list_not_found", Message: "list not found", Err: err}
+		case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+			return &Error{Kind: ErrValidation, Code: "invalid_" + code, Message: "invalid " + subject, Err: err}
+		}
+	}
+	return err
+}
+
+// contextError reports err as a timeout or a cancellation if ctx ended before the database answered,
+// and logs the aborted operation. Errors are returned unchanged while ctx is still live.
+// Either way the error is recorded on the span of the operation.
+func contextError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil && !errors.Is(err, ErrTimeout) && !errors.Is(err, ErrUnavailable) {
+		slog.WarnContext(ctx, "database operation aborted", "reason", ctx.Err().Error(), "error", err)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = &Error{Kind: ErrTimeout, Code: "query_timeout", Message: "the database did not answer in time", Err: err}
+		} else {
+			err = &Error{Kind: ErrUnavailable, Code: "request_cancelled", Message: "the request was cancelled before the database answered", Err: err}
+		}
+	}
+	recordError(ctx, err)
+	return err
+}
+
+// requireRowsAffected turns an UPDATE or DELETE that matched no rows into a not found error
+func requireRowsAffected(result sql.Result, err error, subject string) error {
+	if err != nil {
+		return dbError(err, subject)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return newError(ErrNotFound, strings.ReplaceAll(subject, " ", "_")+"_not_found", "%s not found", subject)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"shopping-api-backend-go/internal/models"
+	"time"
+)
+
+// UnknownActor is recorded for changes made under a context without an actor
+const UnknownActor = "unknown"
+
+// actorKey is the context key of the actor set by WithActor
+type actorKey struct{}
+
+// WithActor returns a context under which the stores record item changes as made by actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set by WithActor, or UnknownActor
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return UnknownActor
+}
+
+// changeTime returns the time a change made now is recorded at, in the microsecond precision of Postgres
+func changeTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// historyName returns the name a change is found under: the name of the item before the change,
+// or after it for creations. Together with the current name of an item, this finds every item
+// that ever had a name, even if it was renamed right after being created.
+func historyName(before, after *models.ShoppingItem) string {
+	if before != nil {
+		return before.Name
+	}
+	return after.Name
+}
+
+// historyItemID returns the ID of the item a change is about
+func historyItemID(before, after *models.ShoppingItem) string {
+	if before != nil {
+		return before.ID
+	}
+	return after.ID
+}
+
+// recordChange appends a change of an item of a list to the item history in the database.
+// It runs in the transaction of the change, so the history never disagrees with the items.
+func recordChange(ctx context.Context, db DBTX, listID string, op models.ItemChangeOp, before, after *models.ShoppingItem) error {
+	states := make([]sql.NullString, 2)
+	for i, item := range []*models.ShoppingItem{before, after} {
+		if item == nil {
+			continue
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		states[i] = sql.NullString{String: string(data), Valid: true}
+	}
+	itemID := historyItemID(before, after)
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO item_history (list_id, item_id, name, op, actor, changed_at, before_item, after_item)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		listID, itemID, historyName(before, after), string(op), ActorFrom(ctx), changeTime(), states[0], states[1])
+	return err
+}
+
+// lastRecordedState returns an item as its latest recorded change left it, or nil if it has none
+func lastRecordedState(ctx context.Context, db DBTX, itemID string) (*models.ShoppingItem, error) {
+	var state sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT after_item FROM item_history WHERE item_id = $1 ORDER BY id DESC LIMIT 1", itemID).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeItemState(state)
+}
+
+// scanChange reads a change selected with the columns of GetItemHistory
+func scanChange(row rowScanner) (models.ItemChange, error) {
+	var change models.ItemChange
+	var before, after sql.NullString
+	if err := row.Scan(&change.ID, &change.ItemID, &change.Op, &change.Actor, &change.At, &before, &after); err != nil {
+		return models.ItemChange{}, err
+	}
+	change.At = change.At.UTC()
+	var err error
+	if change.Before, err = decodeItemState(before); err != nil {
+		return models.ItemChange{}, err
+	}
+	change.After, err = decodeItemState(after)
+	return change, err
+}
+
+// decodeItemState parses an item stored as JSON in the history, or returns nil for NULL
+func decodeItemState(state sql.NullString) (*models.ShoppingItem, error) {
+	if !state.Valid {
+		return nil, nil
+	}
+	var item models.ShoppingItem
+	if err := json.Unmarshal([]byte(state.String), &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetItemHistory retrieves the changes of every item of a list that has or had the name from the
+// database, oldest first. Deleted items are included. It fails with a not found error if no item
+// ever had the name; an existing item without recorded changes has an empty history.
+func GetItemHistory(ctx context.Context, db DBTX, listID, name string) ([]models.ItemChange, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, item_id, op, actor, changed_at, before_item, after_item FROM item_history
+		WHERE item_id IN (
+			SELECT item_id FROM item_history WHERE list_id = $1 AND name = $2
+			UNION SELECT id FROM shopping_items WHERE list_id = $1 AND name = $2)
+		ORDER BY id`, listID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.ItemChange{}
+	for rows.Next() {
+		change, err := scanChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Items from before the history was kept have none until they change
+	if len(changes) == 0 {
+		if _, err := GetItemByName(ctx, db, listID, name); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// itemsAsOf reconstructs the items of a list as they were at asOf from the database. Items changed
+// since then are taken as they were before their first change after asOf, the others as they are now.
+// Both reads share one snapshot; otherwise an item changed in between would be missed by both.
+func itemsAsOf(ctx context.Context, db DBTX, listID string, asOf time.Time) ([]models.ShoppingItem, error) {
+	asOf = asOf.UTC()
+	items := []models.ShoppingItem{}
+	err := withSnapshot(ctx, db, func(tx DBTX) error {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT h.before_item FROM item_history h
+			WHERE h.list_id = $1 AND h.changed_at > $2 AND NOT EXISTS (
+				SELECT 1 FROM item_history e WHERE e.item_id = h.item_id AND e.changed_at > $2 AND e.id < h.id)`, listID, asOf)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var state sql.NullString
+			if err := rows.Scan(&state); err != nil {
+				return err
+			}
+			item, err := decodeItemState(state)
+			if err != nil {
+				return err
+			}
+			if item != nil { // nil if the item was created after asOf
+				items = append(items, *item)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		rows, err = tx.QueryContext(ctx, "SELECT "+itemColumns+` FROM shopping_items i
+			WHERE i.list_id = $1 AND NOT EXISTS (SELECT 1 FROM item_history h WHERE h.item_id = i.id AND h.changed_at > $2)`, listID, asOf)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			item, err := scanItem(rows)
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return rows.Err()
+	})
+	return items, err
+}
+
+// GetItemHistory retrieves the changes of every item of a list that has or had the name from the database
+func (s *SQLStore) GetItemHistory(ctx context.Context, listID, name string) ([]models.ItemChange, error) {
+	ctx, cancel := s.queryContext(ctx, "GetItemHistory")
+	defer cancel()
+	changes, err := GetItemHistory(ctx, s.db, listID, name)
+	return changes, contextError(ctx, err)
+}
@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestItemsAsOf changes items step by step and reconstructs the list as it was after each step
+func TestItemsAsOf(t *testing.T) {
+	for _, s := range testStores(t) {
+		t.Run(s.name, func(t *testing.T) {
+			ctx := services.WithActor(context.Background(), "test")
+			list := services.DefaultListID
+			var times []time.Time
+			mark := func() {
+				time.Sleep(2 * time.Millisecond) // keep the steps apart at the microsecond precision of the history
+				times = append(times, time.Now())
+				time.Sleep(2 * time.Millisecond)
+			}
+
+			mark()
+			added := addItems(t, s.store, models.ShoppingItem{Name: "Milk", Amount: 1}, models.ShoppingItem{Name: "Bread", Amount: 1})
+			milk, bread := added[0], added[1]
+			mark()
+			if _, err := s.store.ModifyItem(ctx, list, milk.ID, func(item *models.ShoppingItem) error { item.Amount = 2; return nil }); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.RenameItem(ctx, list, bread.ID, "Toast"); err != nil {
+				t.Fatal(err)
+			}
+			mark()
+			if err := s.store.DeleteItem(ctx, list, milk.ID, nil); err != nil {
+				t.Fatal(err)
+			}
+			addItems(t, s.store, models.ShoppingItem{Name: "Eggs", Amount: 6})
+			mark()
+
+			tests := []struct {
+				asOf int // index into times, or -1 for now
+				want []string
+			}{
+				{0, []string{}},
+				{1, []string{"Bread:1", "Milk:1"}},
+				{2, []string{"Milk:2", "Toast:1"}},
+				{3, []string{"Eggs:6", "Toast:1"}},
+				{-1, []string{"Eggs:6", "Toast:1"}},
+			}
+			for _, tt := range tests {
+				query := services.ItemQuery{Sort: services.ItemSort{Field: "name"}}
+				if tt.asOf >= 0 {
+					query.AsOf = &times[tt.asOf]
+				}
+				page, err := s.store.GetAllItems(ctx, list, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := []string{}
+				for _, item := range page.Items {
+					got = append(got, fmt.Sprintf("%s:%d", item.Name, item.Amount))
+				}
+				if !reflect.DeepEqual(got, tt.want) || page.Total != len(tt.want) {
+					t.Errorf("items as of step %d = %v (total %d), want %v", tt.asOf, got, page.Total, tt.want)
+				}
+			}
+
+			// The history of a name includes items renamed away from it and deleted ones
+			for name, want := range map[string][]models.ItemChangeOp{
+				"Bread": {models.ItemCreated, models.ItemRenamed},
+				"Toast": {models.ItemCreated, models.ItemRenamed},
+				"Milk":  {models.ItemCreated, models.ItemUpdated, models.ItemDeleted},
+			} {
+				changes, err := s.store.GetItemHistory(ctx, list, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []models.ItemChangeOp
+				for _, change := range changes {
+					got = append(got, change.Op)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("history of %s = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestItemsAsOfSnapshot checks that both reads behind a point-in-time listing run in one transaction.
+// Apart, an item changed between them would be missed by the read of the history, which came too
+// early, and by the read of the current items, which skips items with a change after asOf.
+func TestItemsAsOfSnapshot(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider) // the SQL statements are traced through the global provider
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	store := newSQLiteStore(t)
+	addItems(t, store, models.ShoppingItem{Name: "Milk", Amount: 1})
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+	asOf := time.Now()
+	if _, err := store.GetAllItems(ctx, services.DefaultListID, services.ItemQuery{AsOf: &asOf}); err != nil {
+		t.Fatal(err)
+	}
+	span.End()
+
+	var statements []string
+	for _, s := range recorder.Ended() {
+		if strings.HasPrefix(s.Name(), "sql.") {
+			statements = append(statements, s.Name())
+		}
+	}
+	want := []string{"sql.conn.begin_tx", "sql.conn.query", "sql.conn.query", "sql.tx.commit"}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("statements %v, want %v", statements, want)
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord remembers a request sent with an Idempotency-Key header and the response it got
+type IdempotencyRecord struct {
+	Key         string // the Idempotency-Key, prefixed with the scope of the client that sent it
+	RequestHash string
+	StatusCode  int // 0 while the original request is still being handled
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// IdempotencyStore keeps idempotency records until they expire
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey stores a pending record for a new key. If a record that has not expired
+	// at now already holds the key, it is returned together with an ErrAlreadyExists error.
+	ReserveIdempotencyKey(ctx context.Context, record IdempotencyRecord, now time.Time) (IdempotencyRecord, error)
+	// CompleteIdempotencyKey stores the response of a reserved key
+	CompleteIdempotencyKey(ctx context.Context, record IdempotencyRecord) error
+	// ReleaseIdempotencyKey forgets a reserved key so the request can be retried
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// errIdempotencyKeyTaken is returned by ReserveIdempotencyKey for keys that are already in use
+var errIdempotencyKeyTaken = newError(ErrAlreadyExists, "idempotency_key_taken", "idempotency key already used")
+
+// ReserveIdempotencyKey stores a pending record for a new key in the database, purging expired keys first
+func ReserveIdempotencyKey(ctx context.Context, db DBTX, record IdempotencyRecord, now time.Time) (IdempotencyRecord, error) {
+	if _, err := db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < $1", now.UTC()); err != nil {
+		return IdempotencyRecord{}, err
+	}
+
+	_, err := db.ExecContext(ctx, "INSERT INTO idempotency_keys (idempotency_key, request_hash, expires_at) VALUES ($1, $2, $3)",
+		record.Key, record.RequestHash, record.ExpiresAt.UTC())
+	if err = dbError(err, "idempotency key"); !errors.Is(err, ErrAlreadyExists) {
+		return record, err
+	}
+
+	// Someone else holds the key; return their record so the caller can replay it
+	var existing IdempotencyRecord
+	var header string
+	err = db.QueryRowContext(ctx, "SELECT idempotency_key, request_hash, status_code, header, body, expires_at FROM idempotency_keys WHERE idempotency_key = $1", record.Key).
+		Scan(&existing.Key, &existing.RequestHash, &existing.StatusCode, &header, &existing.Body, &existing.ExpiresAt)
+	if err != nil {
+		return IdempotencyRecord{}, dbError(err, "idempotency key")
+	}
+	if err := json.Unmarshal([]byte(header), &existing.Header); err != nil {
+		return IdempotencyRecord{}, err
+	}
+	return existing, errIdempotencyKeyTaken
+}
+
+// CompleteIdempotencyKey stores the response of a reserved key in the database
+func CompleteIdempotencyKey(ctx context.Context, db DBTX, record IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx, "UPDATE idempotency_keys SET status_code = $1, header = $2, body = $3 WHERE idempotency_key = $4",
+		record.StatusCode, string(header), record.Body, record.Key)
+	return requireRowsAffected(result, err, "idempotency key")
+}
+
+// ReleaseIdempotencyKey deletes a reserved key from the database
+func ReleaseIdempotencyKey(ctx context.Context, db DBTX, key string) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE idempotency_key = $1", key)
+	return err
+}
+
+// ReserveIdempotencyKey stores a pending record for a new key in the database
+func (s *SQLStore) ReserveIdempotencyKey(ctx context.Context, record IdempotencyRecord, now time.Time) (IdempotencyRecord, error) {
+	ctx, cancel := s.queryContext(ctx, "ReserveIdempotencyKey")
+	defer cancel()
+	existing, err := ReserveIdempotencyKey(ctx, s.db, record, now)
+	return existing, contextError(ctx, err)
+}
+
+// CompleteIdempotencyKey stores the response of a reserved key in the database
+func (s *SQLStore) CompleteIdempotencyKey(ctx context.Context, record IdempotencyRecord) error {
+	ctx, cancel := s.queryContext(ctx, "CompleteIdempotencyKey")
+	defer cancel()
+	return contextError(ctx, CompleteIdempotencyKey(ctx, s.db, record))
+}
+
+// ReleaseIdempotencyKey deletes a reserved key from the database
+func (s *SQLStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	ctx, cancel := s.queryContext(ctx, "ReleaseIdempotencyKey")
+	defer cancel()
+	return contextError(ctx, ReleaseIdempotencyKey(ctx, s.db, key))
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"shopping-api-backend-go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Not found errors matching the ones dbError produces for the SQL stores
+var (
+	errItemNotFound = newError(ErrNotFound, "item_not_found", "item not found")
+	errListNotFound = newError(ErrNotFound, "list_not_found", "list not found")
+)
+
+// MemoryStore is an ItemStore that keeps shopping lists and items in memory.
+// It is safe for concurrent use and loses its contents on restart.
+// Its operations never wait on I/O, so they ignore their context.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	lists   map[string]models.ShoppingList
+	items   map[string]map[string]models.ShoppingItem // list ID -> item ID -> item
+	keys    map[string]IdempotencyRecord              // idempotency key -> record
+	history []memoryChange                            // item changes, oldest first
+	inTx    bool                                      // set on the copies WithinTx hands out
+}
+
+// memoryChange is an entry of the item history of a MemoryStore
+type memoryChange struct {
+	listID string
+	name   string // see historyName
+	change models.ItemChange
+}
+
+// NewMemoryStore returns a MemoryStore holding only the empty default list
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		lists: map[string]models.ShoppingList{
+			DefaultListID: {ID: DefaultListID, Name: "Default"},
+		},
+		items: map[string]map[string]models.ShoppingItem{
+			DefaultListID: {},
+		},
+		keys: map[string]IdempotencyRecord{},
+	}
+}
+
+// GetItem retrieves an item of a list by its ID
+func (s *MemoryStore) GetItem(ctx context.Context, listID, id string) (models.ShoppingItem, error) {
+	defer s.rlock()()
+
+	item, ok := s.items[listID][id]
+	if !ok {
+		return models.ShoppingItem{}, errItemNotFound
+	}
+	return item, nil
+}
+
+// GetItemByName retrieves an item of a list by its name
+func (s *MemoryStore) GetItemByName(ctx context.Context, listID, name string) (models.ShoppingItem, error) {
+	defer s.rlock()()
+
+	item, ok := s.findByName(listID, name)
+	if !ok {
+		return models.ShoppingItem{}, errItemNotFound
+	}
+	return item, nil
+}
+
+// ModifyItem lets modify change a shopping item of a list under the store lock. ID and name are left alone.
+func (s *MemoryStore) ModifyItem(ctx context.Context, listID, id string, modify func(*models.ShoppingItem) error) (models.ShoppingItem, error) {
+	defer s.lock()()
+
+	current, ok := s.items[listID][id]
+	if !ok {
+		return models.ShoppingItem{}, errItemNotFound
+	}
+	item := current
+	if err := modify(&item); err != nil {
+		return models.ShoppingItem{}, err
+	}
+	item.ID, item.Name, item.Version = current.ID, current.Name, current.Version+1
+	s.items[listID][id] = item
+	s.record(ctx, listID, models.ItemUpdated, &current, &item)
+	return item, nil
+}
+
+// RenameItem changes the name of a shopping item, returning an ErrConflict error if the list already uses it
+func (s *MemoryStore) RenameItem(ctx context.Context, listID, id, name string) error {
+	defer s.lock()()
+
+	current, ok := s.items[listID][id]
+	if !ok {
+		return errItemNotFound
+	}
+	if current.Name == name {
+		return nil
+	}
+	if _, taken := s.findByName(listID, name); taken {
+		return newError(ErrConflict, "item_name_conflict", "an item named %q already exists in the list", name)
+	}
+	renamed := current
+	renamed.Name = name
+	renamed.Version++
+	s.items[listID][id] = renamed
+	s.record(ctx, listID, models.ItemRenamed, &current, &renamed)
+	return nil
+}
+
+// DeleteItem deletes a shopping item of a list if it passes check
+func (s *MemoryStore) DeleteItem(ctx context.Context, listID, id string, check ItemCheck) error {
+	defer s.lock()()
+
+	item, ok := s.items[listID][id]
+	if !ok {
+		return errItemNotFound
+	}
+	if check != nil {
+		if err := check(item); err != nil {
+			return err
+		}
+	}
+	delete(s.items[listID], id)
+	s.record(ctx, listID, models.ItemDeleted, &item, nil)
+	return nil
+}
+
+// GetAllItems retrieves one page of the shopping items of a list that match the query
+func (s *MemoryStore) GetAllItems(ctx context.Context, listID string, query ItemQuery) (ItemPage, error) {
+	defer s.rlock()()
+
+	if query.AsOf != nil {
+		return query.page(s.itemsAsOf(listID, *query.AsOf)), nil
+	}
+	items := make([]models.ShoppingItem, 0, len(s.items[listID]))
+	for _, item := range s.items[listID] {
+		items = append(items, item)
+	}
+	return query.page(items), nil
+}
+
+// CountItems counts the shopping items of all lists
+func (s *MemoryStore) CountItems(ctx context.Context) (int, error) {
+	defer s.rlock()()
+
+	count := 0
+	for _, items := range s.items {
+		count += len(items)
+	}
+	return count, nil
+}
+
+// AddItem adds a new shopping item to a list, failing if the name is already taken there
+func (s *MemoryStore) AddItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, error) {
+	defer s.lock()()
+
+	items, ok := s.items[listID]
+	if !ok {
+		return models.ShoppingItem{}, errListNotFound
+	}
+	if _, taken := s.findByName(listID, item.Name); taken {
+		return models.ShoppingItem{}, newError(ErrAlreadyExists, "item_already_exists", "item already exists")
+	}
+	item.ID, item.Version = uuid.NewString(), 1
+	items[item.ID] = item
+	s.record(ctx, listID, models.ItemCreated, nil, &item)
+	return item, nil
+}
+
+// UpsertItem adds a shopping item to a list or merges it into the item with the same name.
+// It returns the resulting item and whether it was newly created.
+func (s *MemoryStore) UpsertItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, bool, error) {
+	defer s.lock()()
+
+	items, ok := s.items[listID]
+	if !ok {
+		return models.ShoppingItem{}, false, errListNotFound
+	}
+	current, exists := s.findByName(listID, item.Name)
+	if !exists {
+		item.ID, item.Version = uuid.NewString(), 1
+		items[item.ID] = item
+		s.record(ctx, listID, models.ItemCreated, nil, &item)
+		return item, true, nil
+	}
+
+	// Merge like the ON CONFLICT clause of the SQL stores
+	merged := current
+	merged.Amount += item.Amount
+	if merged.Amount > MaxItemAmount {
+		return models.ShoppingItem{}, false, errAmountLimit
+	}
+	if item.Unit != "" {
+		merged.Unit = item.Unit
+	}
+	if item.Category != "" {
+		merged.Category = item.Category
+	}
+	if item.Notes != "" {
+		merged.Notes = item.Notes
+	}
+	if item.Price != nil {
+		merged.Price = item.Price
+	}
+	if !current.Checked || !item.Checked {
+		merged.CheckedAt = item.CheckedAt
+	}
+	merged.Checked = item.Checked
+	merged.Version++
+	items[merged.ID] = merged
+	s.record(ctx, listID, models.ItemUpdated, &current, &merged)
+	return merged, false, nil
+}
+
+// AdjustItemAmount adds delta to the amount of a shopping item of a list, deleting the item or
+// clamping its amount at zero depending on policy. It returns the item and whether it was deleted.
+func (s *MemoryStore) AdjustItemAmount(ctx context.Context, listID, id string, delta int, policy ZeroPolicy) (models.ShoppingItem, bool, error) {
+	defer s.lock()()
+
+	item, ok := s.items[listID][id]
+	if !ok {
+		return models.ShoppingItem{}, false, errItemNotFound
+	}
+	if item.Amount+delta <= 0 && policy == DeleteAtZero {
+		delete(s.items[listID], id)
+		s.record(ctx, listID, models.ItemDeleted, &item, nil)
+		return item, true, nil
+	}
+	before := item
+	item.Amount = max(item.Amount+delta, 0)
+	if item.Amount > MaxItemAmount {
+		return models.ShoppingItem{}, false, errAmountLimit
+	}
+	item.Version++
+	s.items[listID][id] = item
+	s.record(ctx, listID, models.ItemUpdated, &before, &item)
+	return item, false, nil
+}
+
+// GetAllLists retrieves all shopping lists ordered by name
+func (s *MemoryStore) GetAllLists(ctx context.Context) ([]models.ShoppingList, error) {
+	defer s.rlock()()
+
+	lists := make([]models.ShoppingList, 0, len(s.lists))
+	for _, list := range s.lists {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	return lists, nil
+}
+
+// GetList retrieves a shopping list by its ID
+func (s *MemoryStore) GetList(ctx context.Context, id string) (models.ShoppingList, error) {
+	defer s.rlock()()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return models.ShoppingList{}, errListNotFound
+	}
+	return list, nil
+}
+
+// CreateList creates a new shopping list with a generated ID, failing if the name is already taken
+func (s *MemoryStore) CreateList(ctx context.Context, name string) (models.ShoppingList, error) {
+	defer s.lock()()
+
+	if s.listNameTaken(name) {
+		return models.ShoppingList{}, newError(ErrAlreadyExists, "list_already_exists", "list already exists")
+	}
+	list := models.ShoppingList{ID: uuid.NewString(), Name: name}
+	s.lists[list.ID] = list
+	s.items[list.ID] = map[string]models.ShoppingItem{}
+	return list, nil
+}
+
+// RenameList changes the name of a shopping list
+func (s *MemoryStore) RenameList(ctx context.Context, id, name string) error {
+	defer s.lock()()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return errListNotFound
+	}
+	if list.Name == name {
+		return nil
+	}
+	if s.listNameTaken(name) {
+		return newError(ErrAlreadyExists, "list_already_exists", "list already exists")
+	}
+	list.Name = name
+	s.lists[id] = list
+	return nil
+}
+
+// DeleteList deletes a shopping list together with its items
+func (s *MemoryStore) DeleteList(ctx context.Context, id string) error {
+	if id == DefaultListID {
+		return errDefaultList
+	}
+
+	defer s.lock()()
+
+	if _, ok := s.lists[id]; !ok {
+		return errListNotFound
+	}
+	for _, item := range s.items[id] {
+		s.record(ctx, id, models.ItemDeleted, &item, nil)
+	}
+	delete(s.lists, id)
+	delete(s.items, id)
+	return nil
+}
+
+// GetItemHistory retrieves the changes of every item of a list that has or had the name, oldest first
+func (s *MemoryStore) GetItemHistory(ctx context.Context, listID, name string) ([]models.ItemChange, error) {
+	defer s.rlock()()
+
+	ids := map[string]bool{}
+	if item, ok := s.findByName(listID, name); ok {
+		ids[item.ID] = true
+	}
+	for _, entry := range s.history {
+		if entry.listID == listID && entry.name == name {
+			ids[entry.change.ItemID] = true
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errItemNotFound
+	}
+
+	changes := []models.ItemChange{}
+	for _, entry := range s.history {
+		if ids[entry.change.ItemID] {
+			changes = append(changes, entry.change)
+		}
+	}
+	return changes, nil
+}
+
+// ReserveIdempotencyKey stores a pending record for a new key, purging expired keys first
+func (s *MemoryStore) ReserveIdempotencyKey(ctx context.Context, record IdempotencyRecord, now time.Time) (IdempotencyRecord, error) {
+	defer s.lock()()
+
+	for key, existing := range s.keys {
+		if existing.ExpiresAt.Before(now) {
+			delete(s.keys, key)
+		}
+	}
+	if existing, ok := s.keys[record.Key]; ok {
+		return existing, errIdempotencyKeyTaken
+	}
+	s.keys[record.Key] = record
+	return record, nil
+}
+
+// CompleteIdempotencyKey stores the response of a reserved key
+func (s *MemoryStore) CompleteIdempotencyKey(ctx context.Context, record IdempotencyRecord) error {
+	defer s.lock()()
+
+	if _, ok := s.keys[record.Key]; !ok {
+		return newError(ErrNotFound, "idempotency_key_not_found", "idempotency key not found")
+	}
+	s.keys[record.Key] = record
+	return nil
+}
+
+// ReleaseIdempotencyKey forgets a reserved key
+func (s *MemoryStore) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	defer s.lock()()
+
+	delete(s.keys, key)
+	return nil
+}
+
+// WithinTx runs fn on a copy of the lists and items and keeps the copy's changes only if fn succeeds.
+// The store stays locked meanwhile, so transactions are serialized with every other operation.
+func (s *MemoryStore) WithinTx(ctx context.Context, fn func(tx ItemStore) error) error {
+	defer s.lock()()
+
+	tx := &MemoryStore{
+		lists:   maps.Clone(s.lists),
+		items:   make(map[string]map[string]models.ShoppingItem, len(s.items)),
+		keys:    s.keys,
+		history: slices.Clip(s.history), // appends in tx don't touch s.history
+		inTx:    true,
+	}
+	for listID, items := range s.items {
+		tx.items[listID] = maps.Clone(items)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.lists, s.items, s.history = tx.lists, tx.items, tx.history
+	return nil
+}
+
+// lock takes the write lock and returns the function releasing it.
+// Copies handed out by WithinTx are only used while their parent holds the lock, so they skip it.
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock takes the read lock and returns the function releasing it
+func (s *MemoryStore) rlock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// record appends a change of an item of a list to the history; callers must hold the lock
+func (s *MemoryStore) record(ctx context.Context, listID string, op models.ItemChangeOp, before, after *models.ShoppingItem) {
+	change := models.ItemChange{
+		ID:     int64(len(s.history)) + 1,
+		ItemID: historyItemID(before, after),
+		Op:     op,
+		Actor:  ActorFrom(ctx),
+		At:     changeTime(),
+	}
+	// Copy the items, as callers may go on changing them
+	if before != nil {
+		item := *before
+		change.Before = &item
+	}
+	if after != nil {
+		item := *after
+		change.After = &item
+	}
+	s.history = append(s.history, memoryChange{listID: listID, name: historyName(before, after), change: change})
+}
+
+// itemsAsOf reconstructs the items of a list as they were at asOf, like its SQL counterpart; callers must hold the lock
+func (s *MemoryStore) itemsAsOf(listID string, asOf time.Time) []models.ShoppingItem {
+	items := []models.ShoppingItem{}
+	changed := map[string]bool{}
+	for _, entry := range s.history {
+		change := entry.change
+		if entry.listID != listID || !change.At.After(asOf) || changed[change.ItemID] {
+			continue
+		}
+		changed[change.ItemID] = true
+		if change.Before != nil {
+			items = append(items, *change.Before)
+		}
+	}
+	for id, item := range s.items[listID] {
+		if !changed[id] {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// listNameTaken reports whether a list already uses the name; callers must hold the lock
+func (s *MemoryStore) listNameTaken(name string) bool {
+	for _, list := range s.lists {
+		if list.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findByName looks up an item of a list by its name; callers must hold the lock
+func (s *MemoryStore) findByName(listID, name string) (models.ShoppingItem, bool) {
+	for _, item := range s.items[listID] {
+		if item.Name == name {
+			return item, true
+		}
+	}
+	return models.ShoppingItem{}, false
+}
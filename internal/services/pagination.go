@@ -0,0 +1,154 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"shopping-api-backend-go/internal/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Page sizes for GetAllItems
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// ItemSort orders items by name or amount. Ties are broken by ID so pages stay stable.
+type ItemSort struct {
+	Field string // "name" or "amount"
+	Desc  bool
+}
+
+// ParseItemSort parses a sort parameter such as "name" or "-amount"; a leading "-" sorts descending
+func ParseItemSort(s string) (ItemSort, error) {
+	if s == "" {
+		return ItemSort{Field: "name"}, nil
+	}
+	sort := ItemSort{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+	if sort.Field != "name" && sort.Field != "amount" {
+		return ItemSort{}, newError(ErrValidation, "invalid_sort", "cannot sort by %q, expected name or amount", sort.Field)
+	}
+	return sort, nil
+}
+
+// String formats the sort the way ParseItemSort reads it
+func (s ItemSort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// less reports whether item a comes before item b
+func (s ItemSort) less(a, b models.ShoppingItem) bool {
+	var before, after bool
+	if s.Field == "amount" {
+		before, after = a.Amount < b.Amount, a.Amount > b.Amount
+	} else {
+		before, after = a.Name < b.Name, a.Name > b.Name
+	}
+	if !before && !after {
+		before, after = a.ID < b.ID, a.ID > b.ID
+	}
+	if s.Desc {
+		return after
+	}
+	return before
+}
+
+// orderBy returns the SQL ORDER BY clause for the sort
+func (s ItemSort) orderBy() string {
+	dir := "ASC"
+	if s.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, id %s", s.Field, dir, dir)
+}
+
+// ItemCursor marks the last item of a page; the next page starts after it
+type ItemCursor struct {
+	Sort   string `json:"s"`
+	Name   string `json:"n"`
+	Amount int    `json:"a"`
+	ID     string `json:"i"`
+}
+
+// newCursor returns the opaque cursor pointing after an item
+func newCursor(sort ItemSort, item models.ShoppingItem) string {
+	data, _ := json.Marshal(ItemCursor{Sort: sort.String(), Name: item.Name, Amount: item.Amount, ID: item.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses an opaque cursor returned with a previous page of the same sort
+func DecodeCursor(raw string, sort ItemSort) (*ItemCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, newError(ErrValidation, "invalid_cursor", "malformed cursor")
+	}
+	var cursor ItemCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, newError(ErrValidation, "invalid_cursor", "malformed cursor")
+	}
+	if cursor.Sort != sort.String() {
+		return nil, newError(ErrValidation, "invalid_cursor", "cursor was issued for sort %q", cursor.Sort)
+	}
+	return &cursor, nil
+}
+
+// item returns the cursor position as an item so it can be compared with ItemSort.less
+func (c *ItemCursor) item() models.ShoppingItem {
+	return models.ShoppingItem{ID: c.ID, Name: c.Name, Amount: c.Amount}
+}
+
+// ItemQuery selects one page of the filtered items of a list
+type ItemQuery struct {
+	ItemFilter
+	Sort  ItemSort
+	Limit int         // page size; 0 means DefaultPageSize
+	After *ItemCursor // start after this item; nil for the first page
+	AsOf  *time.Time  // reconstruct the items as they were at this time from their history; nil for now
+}
+
+// pageSize returns the effective page size of the query
+func (q ItemQuery) pageSize() int {
+	if q.Limit <= 0 {
+		return DefaultPageSize
+	}
+	return q.Limit
+}
+
+// page picks the page of the query from all items of a list, for stores that filter and sort in memory
+func (q ItemQuery) page(all []models.ShoppingItem) ItemPage {
+	items := []models.ShoppingItem{}
+	for _, item := range all {
+		if q.Matches(item) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return q.Sort.less(items[i], items[j]) })
+	page := ItemPage{Total: len(items)}
+
+	// Skip to the first item after the cursor
+	if q.After != nil {
+		last := q.After.item()
+		start := sort.Search(len(items), func(i int) bool { return q.Sort.less(last, items[i]) })
+		items = items[start:]
+	}
+
+	if size := q.pageSize(); len(items) > size {
+		items = items[:size]
+		page.NextCursor = newCursor(q.Sort, items[size-1])
+	}
+	page.Items = items
+	return page
+}
+
+// ItemPage is one page of items together with the paging metadata
+type ItemPage struct {
+	Items      []models.ShoppingItem
+	NextCursor string // empty on the last page
+	Total      int    // number of items matching the filter across all pages
+}
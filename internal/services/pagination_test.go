@@ -0,0 +1,149 @@
+package services_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+)
+
+func TestParseItemSort(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    services.ItemSort
+		wantErr bool
+	}{
+		{"", services.ItemSort{Field: "name"}, false},
+		{"name", services.ItemSort{Field: "name"}, false},
+		{"-amount", services.ItemSort{Field: "amount", Desc: true}, false},
+		{"price", services.ItemSort{}, true},
+		{"--name", services.ItemSort{}, true},
+	}
+	for _, tt := range tests {
+		got, err := services.ParseItemSort(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseItemSort(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+		if err == nil && tt.in != "" && got.String() != tt.in {
+			t.Errorf("ParseItemSort(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	byName := services.ItemSort{Field: "name"}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name    string
+		raw     string
+		want    *services.ItemCursor
+		wantErr bool
+	}{
+		{"valid", encode(`{"s":"name","n":"Milk","a":2,"i":"id-1"}`), &services.ItemCursor{Sort: "name", Name: "Milk", Amount: 2, ID: "id-1"}, false},
+		{"not base64", "!!!", nil, true},
+		{"not JSON", encode("milk"), nil, true},
+		{"no ID", encode(`{"s":"name","n":"Milk"}`), nil, true},
+		{"other sort", encode(`{"s":"-amount","n":"Milk","a":2,"i":"id-1"}`), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := services.DecodeCursor(tt.raw, byName)
+			if tt.wantErr {
+				if !errors.Is(err, services.ErrValidation) || services.ErrorCode(err) != "invalid_cursor" {
+					t.Fatalf("DecodeCursor() error = %v, want invalid_cursor", err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("DecodeCursor() = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetAllItemsPages walks the pages of every sort and checks that the cursors continue right after
+// the last item of the previous page, including across items that tie on the sort field
+func TestGetAllItemsPages(t *testing.T) {
+	items := []models.ShoppingItem{
+		{Name: "Bread", Amount: 2},
+		{Name: "Apples", Amount: 6},
+		{Name: "Eggs", Amount: 2},
+		{Name: "Cheese", Amount: 1},
+		{Name: "Milk", Amount: 2},
+	}
+	tests := []struct {
+		sort  string
+		limit int
+		want  []string
+	}{
+		{"name", 2, []string{"Apples", "Bread", "Cheese", "Eggs", "Milk"}},
+		{"-name", 3, []string{"Milk", "Eggs", "Cheese", "Bread", "Apples"}},
+		{"amount", 2, nil}, // ties on amount are ordered by the generated IDs, see below
+		{"-amount", 1, nil},
+	}
+	for _, s := range testStores(t) {
+		added := addItems(t, s.store, items...)
+		for _, tt := range tests {
+			t.Run(s.name+"/"+tt.sort, func(t *testing.T) {
+				ctx := context.Background()
+				order, err := services.ParseItemSort(tt.sort)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := tt.want
+				if want == nil {
+					want = itemNames(sortedByAmount(added, order.Desc))
+				}
+
+				var got []string
+				query := services.ItemQuery{Sort: order, Limit: tt.limit}
+				for pages := 0; ; pages++ {
+					if pages > len(items) {
+						t.Fatal("paging does not end")
+					}
+					page, err := s.store.GetAllItems(ctx, services.DefaultListID, query)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if page.Total != len(items) {
+						t.Errorf("Total = %d, want %d", page.Total, len(items))
+					}
+					if len(page.Items) > tt.limit {
+						t.Errorf("page of %d items, want at most %d", len(page.Items), tt.limit)
+					}
+					got = append(got, itemNames(page.Items)...)
+					if page.NextCursor == "" {
+						break
+					}
+					if query.After, err = services.DecodeCursor(page.NextCursor, order); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("pages = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+// sortedByAmount orders items by amount and then ID, the way the stores break ties
+func sortedByAmount(items []models.ShoppingItem, desc bool) []models.ShoppingItem {
+	sorted := append([]models.ShoppingItem(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if desc {
+			a, b = b, a
+		}
+		if a.Amount != b.Amount {
+			return a.Amount < b.Amount
+		}
+		return a.ID < b.ID
+	})
+	return sorted
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"shopping-api-backend-go/internal/models"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Media types accepted by ApplyItemPatch
+const (
+	MergePatchType = "application/merge-patch+json" // RFC 7396
+	JSONPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+// ApplyItemPatch applies a JSON Merge Patch or JSON Patch document of the given media type to an item.
+// A failed JSON Patch test operation is a conflict; any other problem with the patch is a validation error.
+func ApplyItemPatch(item *models.ShoppingItem, mediaType string, patch []byte) error {
+	original, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch mediaType {
+	case MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case JSONPatchType:
+		var ops jsonpatch.Patch
+		if ops, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = ops.Apply(original)
+		}
+	default:
+		return newError(ErrValidation, "unsupported_patch_type", "patches must be %s or %s", MergePatchType, JSONPatchType)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return newError(ErrConflict, "patch_test_failed", "%v", err)
+	}
+	if err != nil {
+		return newError(ErrValidation, "invalid_patch", "cannot apply patch: %v", err)
+	}
+
+	var result models.ShoppingItem
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return newError(ErrValidation, "invalid_patch", "patched item is not a valid shopping item: %v", err)
+	}
+	*item = result
+	return nil
+}
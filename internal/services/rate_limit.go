@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket policy: a bucket holds up to Limit tokens and is refilled evenly
+// at Limit tokens per Window. Every request takes a token. A zero Limit means no limit.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRateLimit parses a policy such as "300/1m", or "off" for no limit
+func ParseRateLimit(s string) (RateLimit, error) {
+	if s == "off" || s == "0" {
+		return RateLimit{}, nil
+	}
+	count, window, ok := strings.Cut(s, "/")
+	limit, err := strconv.Atoi(count)
+	if !ok || err != nil || limit < 0 {
+		return RateLimit{}, fmt.Errorf("%q is not a rate limit such as 300/1m or off", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("%q is not a rate limit such as 300/1m or off", s)
+	}
+	return RateLimit{Limit: limit, Window: d}, nil
+}
+
+// String formats the policy the way ParseRateLimit reads it
+func (l RateLimit) String() string {
+	if l.Limit == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Limit, l.Window)
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // whole tokens left in the bucket
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next token, if the request was not allowed
+}
+
+// RateLimitStore keeps the token buckets of clients. Stores shared by several
+// servers, such as the database, make them share the limits.
+type RateLimitStore interface {
+	// TakeRateLimitToken refills the bucket of key for the time passed until now and takes a token if there is one
+	TakeRateLimitToken(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+// bucket is the state of a token bucket
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// fullBucket returns the bucket of a client that hasn't sent a request yet
+func (l RateLimit) fullBucket(now time.Time) bucket {
+	return bucket{tokens: float64(l.Limit), updatedAt: now}
+}
+
+// take refills b for the time passed since its last update and takes a token if there is one
+func (l RateLimit) take(b bucket, now time.Time) (bucket, RateLimitResult) {
+	elapsed := max(now.Sub(b.updatedAt).Seconds(), 0) // clocks of several servers may disagree
+	tokens := math.Min(float64(l.Limit), b.tokens+elapsed*l.perSecond())
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return bucket{tokens: tokens, updatedAt: now}, l.result(tokens, allowed)
+}
+
+// result describes a bucket left with tokens after a request
+func (l RateLimit) result(tokens float64, allowed bool) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(l.Limit) - tokens) / l.perSecond()),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / l.perSecond())
+	}
+	return result
+}
+
+// perSecond returns the refill rate of the bucket
+func (l RateLimit) perSecond() float64 {
+	return float64(l.Limit) / l.Window.Seconds()
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// MemoryRateLimiter is a RateLimitStore that keeps the buckets in memory, so every server has its own
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]bucket
+	fullAt    map[string]time.Time // when each bucket is full again and can be forgotten
+	lastPurge time.Time
+}
+
+// rateLimitPurgeInterval is how often MemoryRateLimiter forgets full buckets
+const rateLimitPurgeInterval = time.Minute
+
+// NewMemoryRateLimiter returns a MemoryRateLimiter without buckets
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: map[string]bucket{}, fullAt: map[string]time.Time{}}
+}
+
+// TakeRateLimitToken takes a token from the bucket of key, forgetting full buckets from time to time
+func (l *MemoryRateLimiter) TakeRateLimitToken(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastPurge) >= rateLimitPurgeInterval {
+		for k, fullAt := range l.fullAt {
+			if fullAt.Before(now) {
+				delete(l.buckets, k)
+				delete(l.fullAt, k)
+			}
+		}
+		l.lastPurge = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = limit.fullBucket(now)
+	}
+	b, result := limit.take(b, now)
+	l.buckets[key] = b
+	l.fullAt[key] = now.Add(result.Reset)
+	return result, nil
+}
+
+// maxRateLimitAttempts bounds how often TakeRateLimitToken starts over when a bucket is
+// created or refilled between its statements
+const maxRateLimitAttempts = 3
+
+// errRateLimitContention is returned when a bucket kept changing under TakeRateLimitToken
+var errRateLimitContention = newError(ErrConflict, "rate_limit_contention", "the rate limit was updated concurrently")
+
+// refilledTokens is the SQL expression of the tokens in a bucket at $1 (Unix seconds), refilled at
+// $2 tokens per second up to $3. A bucket updated later than $1 by a server with a clock ahead isn't refilled.
+const refilledTokens = "(CASE WHEN tokens + (CASE WHEN $1 > updated_unix THEN $1 - updated_unix ELSE 0 END) * $2 > $3" +
+	" THEN $3 ELSE tokens + (CASE WHEN $1 > updated_unix THEN $1 - updated_unix ELSE 0 END) * $2 END)"
+
+// TakeRateLimitToken takes a token from the bucket of key in the database. A single UPDATE refills the
+// bucket and takes the token if there is one, so servers sharing the database never lose or double-spend
+// a token. Requests over the limit only read the bucket. Creating a bucket purges the ones that are full again.
+func TakeRateLimitToken(ctx context.Context, db DBTX, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	unix := float64(now.UnixNano()) / 1e9
+	for attempt := 0; attempt < maxRateLimitAttempts; attempt++ {
+		var tokens float64
+		err := db.QueryRowContext(ctx, "UPDATE rate_limits SET tokens = "+refilledTokens+" - 1,"+
+			" updated_unix = CASE WHEN $1 > updated_unix THEN $1 ELSE updated_unix END, full_unix = $1 + $4"+
+			" WHERE bucket_key = $5 AND "+refilledTokens+" >= 1 RETURNING tokens",
+			unix, limit.perSecond(), float64(limit.Limit), limit.Window.Seconds(), key).Scan(&tokens)
+		if err == nil {
+			return limit.result(tokens, true), nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return RateLimitResult{}, err
+		}
+
+		// The bucket is empty or doesn't exist yet
+		var b bucket
+		var updatedUnix float64
+		err = db.QueryRowContext(ctx, "SELECT tokens, updated_unix FROM rate_limits WHERE bucket_key = $1", key).Scan(&b.tokens, &updatedUnix)
+		if err == nil {
+			b.updatedAt = time.Unix(0, int64(updatedUnix*1e9))
+			if _, result := limit.take(b, now); !result.Allowed {
+				return result, nil
+			}
+			continue // refilled since the UPDATE
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return RateLimitResult{}, err
+		}
+		if _, err := db.ExecContext(ctx, "DELETE FROM rate_limits WHERE full_unix < $1", unix); err != nil {
+			return RateLimitResult{}, err
+		}
+		b, result := limit.take(limit.fullBucket(now), now)
+		inserted, err := db.ExecContext(ctx,
+			"INSERT INTO rate_limits (bucket_key, tokens, updated_unix, full_unix) VALUES ($1, $2, $3, $4) ON CONFLICT (bucket_key) DO NOTHING",
+			key, b.tokens, unix, unix+limit.Window.Seconds())
+		if err != nil {
+			return RateLimitResult{}, err
+		}
+		if n, err := inserted.RowsAffected(); n == 1 || err != nil {
+			return result, err
+		}
+		// Another request created the bucket first
+	}
+	return RateLimitResult{}, errRateLimitContention
+}
+
+// TakeRateLimitToken takes a token from the bucket of key in the database
+func (s *SQLStore) TakeRateLimitToken(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	ctx, cancel := s.queryContext(ctx, "TakeRateLimitToken")
+	defer cancel()
+	result, err := TakeRateLimitToken(ctx, s.db, key, limit, now)
+	return result, contextError(ctx, err)
+}
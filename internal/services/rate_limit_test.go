@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"shopping-api-backend-go/internal/services"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    services.RateLimit
+		wantErr bool
+	}{
+		{"300/1m", services.RateLimit{Limit: 300, Window: time.Minute}, false},
+		{"5/10s", services.RateLimit{Limit: 5, Window: 10 * time.Second}, false},
+		{"off", services.RateLimit{}, false},
+		{"300", services.RateLimit{}, true},
+		{"0", services.RateLimit{}, false},
+		{"-1/1m", services.RateLimit{}, true},
+		{"10/0s", services.RateLimit{}, true},
+		{"many/1m", services.RateLimit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := services.ParseRateLimit(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRateLimit(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestTakeRateLimitToken drains and refills a bucket of 3 tokens per 3 seconds in both rate limit stores
+func TestTakeRateLimitToken(t *testing.T) {
+	limit := services.RateLimit{Limit: 3, Window: 3 * time.Second}
+	start := time.Unix(1_700_000_000, 0) // whole seconds keep the SQL arithmetic exact
+	steps := []struct {
+		key     string
+		after   time.Duration // since start
+		want    services.RateLimitResult
+		comment string
+	}{
+		{"a", 0, services.RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}, "new bucket"},
+		{"a", 0, services.RateLimitResult{Allowed: true, Remaining: 1, Reset: 2 * time.Second}, ""},
+		{"a", 0, services.RateLimitResult{Allowed: true, Remaining: 0, Reset: 3 * time.Second}, "last token"},
+		{"a", 0, services.RateLimitResult{Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}, "empty"},
+		{"b", 0, services.RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}, "other keys have their own bucket"},
+		{"a", 500 * time.Millisecond, services.RateLimitResult{Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}, "half a token"},
+		{"a", time.Second, services.RateLimitResult{Allowed: true, Remaining: 0, Reset: 3 * time.Second}, "refilled one token"},
+		{"a", time.Minute, services.RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}, "refilled no further than full"},
+		{"a", 59 * time.Second, services.RateLimitResult{Allowed: true, Remaining: 1, Reset: 2 * time.Second}, "clock behind the bucket"},
+	}
+	stores := []struct {
+		name  string
+		store services.RateLimitStore
+	}{
+		{"memory", services.NewMemoryRateLimiter()},
+		{"sqlite", newSQLiteStore(t)},
+	}
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			for i, step := range steps {
+				got, err := s.store.TakeRateLimitToken(context.Background(), step.key, limit, start.Add(step.after))
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+				if got != step.want {
+					t.Errorf("step %d (%s): got %+v, want %+v", i, step.comment, got, step.want)
+				}
+			}
+		})
+	}
+}
@@ -1,103 +1,522 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 	"shopping-api-backend-go/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sethvargo/go-retry"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// DB holds the global database connection.
-var db *sql.DB
+// PoolConfig limits the connection pool of a database; zero values keep the database/sql defaults
+type PoolConfig struct {
+	MaxOpenConns    int           // 0 means unlimited
+	MaxIdleConns    int           // 0 means the database/sql default of 2
+	ConnMaxLifetime time.Duration // 0 means connections are reused forever
+}
 
-// InitDB initializes and returns the database connection
-func InitDB() *sql.DB {
-	dbHost := os.Getenv("POSTGRES_HOST")
-	dbPort := os.Getenv("POSTGRES_PORT")
-	dbUser := os.Getenv("POSTGRES_USER")
-	dbPassword := os.Getenv("POSTGRES_PASSWORD")
-	dbName := os.Getenv("POSTGRES_DB")
+// Backoff between connection attempts in InitDB
+const (
+	connectBackoffBase = 500 * time.Millisecond
+	connectBackoffMax  = 10 * time.Second
+	connectAttemptMax  = 5 * time.Second // longest a single ping may take
+)
 
-	dbURL := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", dbHost, dbPort, dbUser, dbPassword, dbName)
-	var err error
-	db, err = sql.Open("postgres", dbURL)
+// InitDB opens the PostgreSQL database named by a lib/pq connection string or URL and waits until it answers.
+// Failed pings are retried with exponential backoff and jitter until ctx ends, since the database often
+// starts after the server in docker-compose and Kubernetes.
+func InitDB(ctx context.Context, dsn string, pool PoolConfig) (*sql.DB, error) {
+	db, err := openDB("postgres", dsn, semconv.DBSystemPostgreSQL)
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to connect to the database: %v", err)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	if pool.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(pool.MaxIdleConns)
 	}
-	return db
-}
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
-// DB function returns the global database connection.
-func DB() *sql.DB {
-	if db == nil {
-		db = InitDB()
+	backoff := retry.WithJitterPercent(20, retry.WithCappedDuration(connectBackoffMax, retry.NewExponential(connectBackoffBase)))
+	attempt := 0
+	var lastErr error
+	err = retry.Do(ctx, backoff, func(ctx context.Context) error {
+		attempt++
+		pingCtx, cancel := context.WithTimeout(ctx, connectAttemptMax)
+		defer cancel()
+		if lastErr = db.PingContext(pingCtx); lastErr != nil {
+			slog.WarnContext(ctx, "database not reachable yet, retrying", "attempt", attempt, "error", lastErr)
+			return retry.RetryableError(lastErr)
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		if lastErr != nil {
+			err = lastErr
+		}
+		return nil, fmt.Errorf("failed to connect to the database after %d attempts: %w", attempt, err)
 	}
-	return db
+	return db, nil
 }
 
-// CreateTableIfNotExists creates the shopping_items table if it doesn't exist
-func CreateTableIfNotExists(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS shopping_items (
-			name TEXT PRIMARY KEY,
-			amount INTEGER NOT NULL
-		)
-	`)
-	return err
+// itemColumns lists the shopping_items columns in the order scanItem reads them
+const itemColumns = "id, name, amount, unit, category, notes, price, checked, checked_at, version"
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
 }
 
-// GetItemByName retrieves an item by its name from the database
-func GetItemByName(db *sql.DB, name string) (models.ShoppingItem, error) {
+// scanItem reads a shopping item selected with itemColumns
+func scanItem(row rowScanner) (models.ShoppingItem, error) {
 	var item models.ShoppingItem
-	err := db.QueryRow("SELECT name, amount FROM shopping_items WHERE name = $1", name).Scan(&item.Name, &item.Amount)
+	err := row.Scan(&item.ID, &item.Name, &item.Amount, &item.Unit, &item.Category, &item.Notes, &item.Price, &item.Checked, &item.CheckedAt, &item.Version)
 	return item, err
 }
 
-// UpdateItem updates an existing shopping item
-func UpdateItem(db *sql.DB, name string, item models.ShoppingItem) error {
-	_, err := db.Exec("UPDATE shopping_items SET name = $1, amount = $2 WHERE name = $3", item.Name, item.Amount, name)
-	return err
+// GetItem retrieves an item of a list by its ID from the database
+func GetItem(ctx context.Context, db DBTX, listID, id string) (models.ShoppingItem, error) {
+	item, err := scanItem(db.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM shopping_items WHERE list_id = $1 AND id = $2", listID, id))
+	return item, dbError(err, "item")
 }
 
-// DeleteItem deletes a shopping item from the database
-func DeleteItem(db *sql.DB, name string) error {
-	_, err := db.Exec("DELETE FROM shopping_items WHERE name = $1", name)
-	return err
+// GetItemByName retrieves an item of a list by its name from the database
+func GetItemByName(ctx context.Context, db DBTX, listID, name string) (models.ShoppingItem, error) {
+	item, err := scanItem(db.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM shopping_items WHERE list_id = $1 AND name = $2", listID, name))
+	return item, dbError(err, "item")
 }
 
-// GetAllItems retrieves all shopping items from the database
-func GetAllItems(db *sql.DB) ([]models.ShoppingItem, error) {
-	rows, err := db.Query("SELECT name, amount FROM shopping_items")
-	if err != nil {
+// updateItemSQL writes every column of an item except its ID and name and increments its version
+const updateItemSQL = `
+	UPDATE shopping_items
+	SET amount = $1, unit = $2, category = $3, notes = $4, price = $5, checked = $6, checked_at = $7, version = version + 1
+	WHERE list_id = $8 AND id = $9`
+
+// lockItem selects a shopping item of a list inside a transaction and locks it until the transaction ends.
+// The no-op UPDATE takes the row lock in Postgres and the write lock in SQLite, which lacks SELECT ... FOR UPDATE.
+func lockItem(ctx context.Context, tx DBTX, listID, id string) (models.ShoppingItem, error) {
+	result, err := tx.ExecContext(ctx, "UPDATE shopping_items SET version = version WHERE list_id = $1 AND id = $2", listID, id)
+	if err := requireRowsAffected(result, err, "item"); err != nil {
+		return models.ShoppingItem{}, err
+	}
+	return GetItem(ctx, tx, listID, id)
+}
+
+// lockItemByName is lockItem for an item addressed by name. It returns nil without an error
+// if the list has no item with the name.
+func lockItemByName(ctx context.Context, tx DBTX, listID, name string) (*models.ShoppingItem, error) {
+	result, err := tx.ExecContext(ctx, "UPDATE shopping_items SET version = version WHERE list_id = $1 AND name = $2", listID, name)
+	if err = requireRowsAffected(result, err, "item"); errors.Is(err, ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
+	item, err := GetItemByName(ctx, tx, listID, name)
+	return &item, err
+}
+
+// ModifyItem reads a shopping item of a list, lets modify change it and writes the result back in one transaction.
+// The row stays locked in between, so concurrent modifications cannot overwrite each other.
+// An error from modify aborts the transaction and is returned as is. ID and name are left alone.
+func ModifyItem(ctx context.Context, db DBTX, listID, id string, modify func(*models.ShoppingItem) error) (models.ShoppingItem, error) {
+	var item models.ShoppingItem
+	err := withTx(ctx, db, func(tx DBTX) error {
+		current, err := lockItem(ctx, tx, listID, id)
+		if err != nil {
+			return err
+		}
+
+		item = current
+		if err := modify(&item); err != nil {
+			return err
+		}
+		item.ID, item.Name, item.Version = current.ID, current.Name, current.Version+1
+		if _, err := tx.ExecContext(ctx, updateItemSQL,
+			item.Amount, item.Unit, item.Category, item.Notes, item.Price, item.Checked, item.CheckedAt, listID, id); err != nil {
+			return dbError(err, "item")
+		}
+		return recordChange(ctx, tx, listID, models.ItemUpdated, &current, &item)
+	})
+	if err != nil {
+		return models.ShoppingItem{}, err
+	}
+	return item, nil
+}
+
+// RenameItem changes the name of a shopping item, returning an ErrConflict error if the list already uses it
+func RenameItem(ctx context.Context, db DBTX, listID, id, name string) error {
+	return withTx(ctx, db, func(tx DBTX) error {
+		before, err := lockItem(ctx, tx, listID, id)
+		if err != nil || before.Name == name {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE shopping_items SET name = $1, version = version + 1 WHERE list_id = $2 AND id = $3", name, listID, id)
+		if err = dbError(err, "item"); errors.Is(err, ErrAlreadyExists) {
+			return newError(ErrConflict, "item_name_conflict", "an item named %q already exists in the list", name)
+		} else if err != nil {
+			return err
+		}
+		after := before
+		after.Name, after.Version = name, before.Version+1
+		return recordChange(ctx, tx, listID, models.ItemRenamed, &before, &after)
+	})
+}
+
+// DeleteItem deletes a shopping item of a list from the database if it passes check
+func DeleteItem(ctx context.Context, db DBTX, listID, id string, check ItemCheck) error {
+	return withTx(ctx, db, func(tx DBTX) error {
+		item, err := lockItem(ctx, tx, listID, id)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			if err := check(item); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM shopping_items WHERE list_id = $1 AND id = $2", listID, id); err != nil {
+			return dbError(err, "item")
+		}
+		return recordChange(ctx, tx, listID, models.ItemDeleted, &item, nil)
+	})
+}
+
+// CountItems counts the shopping items of all lists in the database
+func CountItems(ctx context.Context, db DBTX) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shopping_items").Scan(&count)
+	return count, err
+}
+
+// GetAllItems retrieves one page of the shopping items of a list that match the query from the database
+func GetAllItems(ctx context.Context, db DBTX, listID string, query ItemQuery) (ItemPage, error) {
+	if query.AsOf != nil {
+		items, err := itemsAsOf(ctx, db, listID, *query.AsOf)
+		if err != nil {
+			return ItemPage{}, err
+		}
+		return query.page(items), nil
+	}
+
+	where := query.where(listID)
+
+	// Count the matches across all pages before narrowing down to this page
+	page := ItemPage{Items: []models.ShoppingItem{}}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM shopping_items WHERE "+where.String(), where.args...).Scan(&page.Total); err != nil {
+		return ItemPage{}, err
+	}
+
+	if c := query.After; c != nil {
+		op := ">"
+		if query.Sort.Desc {
+			op = "<"
+		}
+		var value interface{} = c.Name
+		if query.Sort.Field == "amount" {
+			value = c.Amount
+		}
+		where.add(fmt.Sprintf("(%[1]s %[2]s $%%d OR (%[1]s = $%%d AND id %[2]s $%%d))", query.Sort.Field, op), value, value, c.ID)
+	}
+
+	// Fetch one extra row to find out whether there is a next page
+	size := query.pageSize()
+	rows, err := db.QueryContext(ctx, "SELECT "+itemColumns+" FROM shopping_items WHERE "+where.String()+
+		" ORDER BY "+query.Sort.orderBy()+fmt.Sprintf(" LIMIT %d", size+1), where.args...)
+	if err != nil {
+		return ItemPage{}, err
+	}
 	defer rows.Close()
 
-	var items []models.ShoppingItem
 	for rows.Next() {
-		var item models.ShoppingItem
-		if err := rows.Scan(&item.Name, &item.Amount); err != nil {
-			return nil, err
+		item, err := scanItem(rows)
+		if err != nil {
+			return ItemPage{}, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return ItemPage{}, err
+	}
+
+	if len(page.Items) > size {
+		page.Items = page.Items[:size]
+		page.NextCursor = newCursor(query.Sort, page.Items[size-1])
+	}
+	return page, nil
+}
+
+// AddItem adds a new shopping item to a list and returns it with its generated ID
+func AddItem(ctx context.Context, db DBTX, listID string, item models.ShoppingItem) (models.ShoppingItem, error) {
+	item.ID, item.Version = uuid.NewString(), 1
+	err := withTx(ctx, db, func(tx DBTX) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO shopping_items (id, list_id, name, amount, unit, category, notes, price, checked, checked_at, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			item.ID, listID, item.Name, item.Amount, item.Unit, item.Category, item.Notes, item.Price, item.Checked, item.CheckedAt, item.Version)
+		if err != nil {
+			return dbError(err, "item")
+		}
+		return recordChange(ctx, tx, listID, models.ItemCreated, nil, &item)
+	})
+	if err != nil {
+		return models.ShoppingItem{}, err
+	}
+	return item, nil
+}
+
+// UpsertItem adds a shopping item to a list or, if the list already has an item with that name, merges it into
+// that item. Merging adds the amounts, takes over the unit, category, notes and price that are set and the
+// checked state, and increments the version. It returns the resulting item and whether it was newly created.
+func UpsertItem(ctx context.Context, db DBTX, listID string, item models.ShoppingItem) (models.ShoppingItem, bool, error) {
+	id := uuid.NewString()
+	var upserted models.ShoppingItem
+	err := withTx(ctx, db, func(tx DBTX) error {
+		// Lock the item the new one may be merged into, to record how it was before
+		before, err := lockItemByName(ctx, tx, listID, item.Name)
+		if err != nil {
+			return err
+		}
+		upserted, err = scanItem(tx.QueryRowContext(ctx, `
+			INSERT INTO shopping_items (id, list_id, name, amount, unit, category, notes, price, checked, checked_at, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1)
+			ON CONFLICT (list_id, name) DO UPDATE SET
+				amount = shopping_items.amount + excluded.amount,
+				unit = CASE WHEN excluded.unit = '' THEN shopping_items.unit ELSE excluded.unit END,
+				category = CASE WHEN excluded.category = '' THEN shopping_items.category ELSE excluded.category END,
+				notes = CASE WHEN excluded.notes = '' THEN shopping_items.notes ELSE excluded.notes END,
+				price = COALESCE(excluded.price, shopping_items.price),
+				checked = excluded.checked,
+				checked_at = CASE WHEN shopping_items.checked AND excluded.checked THEN shopping_items.checked_at ELSE excluded.checked_at END,
+				version = shopping_items.version + 1
+			RETURNING `+itemColumns,
+			id, listID, item.Name, item.Amount, item.Unit, item.Category, item.Notes, item.Price, item.Checked, item.CheckedAt))
+		if err != nil {
+			return dbError(err, "item")
+		}
+		if upserted.Amount > MaxItemAmount {
+			return errAmountLimit
+		}
+		if upserted.ID == id {
+			return recordChange(ctx, tx, listID, models.ItemCreated, nil, &upserted)
 		}
-		items = append(items, item)
+		if before == nil {
+			// Another transaction created the item after the lock was tried; it recorded how it looked
+			if before, err = lastRecordedState(ctx, tx, upserted.ID); err != nil {
+				return err
+			}
+		}
+		return recordChange(ctx, tx, listID, models.ItemUpdated, before, &upserted)
+	})
+	if err != nil {
+		return models.ShoppingItem{}, false, err
 	}
+	return upserted, upserted.ID == id, nil
+}
+
+// AdjustItemAmount atomically adds delta, which may be negative, to the amount of a shopping item of a list.
+// If the amount drops to zero or below, the item is deleted or kept with an amount of zero depending on policy.
+// It returns the item after the change, or as it was before it was deleted, and whether it was deleted.
+func AdjustItemAmount(ctx context.Context, db DBTX, listID, id string, delta int, policy ZeroPolicy) (models.ShoppingItem, bool, error) {
+	var item models.ShoppingItem
+	deleted := false
+	err := withTx(ctx, db, func(tx DBTX) error {
+		before, err := lockItem(ctx, tx, listID, id)
+		if err != nil {
+			return err
+		}
+		if policy == DeleteAtZero && before.Amount+delta <= 0 {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM shopping_items WHERE list_id = $1 AND id = $2", listID, id); err != nil {
+				return dbError(err, "item")
+			}
+			item, deleted = before, true
+			return recordChange(ctx, tx, listID, models.ItemDeleted, &before, nil)
+		}
 
-	// If no items were found, ensure we return an empty array
-	if len(items) == 0 {
-		return []models.ShoppingItem{}, nil // Return an empty array instead of nil
+		item, err = scanItem(tx.QueryRowContext(ctx, `
+			UPDATE shopping_items
+			SET amount = CASE WHEN amount + $1 < 0 THEN 0 ELSE amount + $1 END, version = version + 1
+			WHERE list_id = $2 AND id = $3
+			RETURNING `+itemColumns, delta, listID, id))
+		if err != nil {
+			return dbError(err, "item")
+		}
+		if item.Amount > MaxItemAmount {
+			return errAmountLimit
+		}
+		return recordChange(ctx, tx, listID, models.ItemUpdated, &before, &item)
+	})
+	if err != nil {
+		return models.ShoppingItem{}, false, err
 	}
+	return item, deleted, nil
+}
+
+// DefaultQueryTimeout bounds each store operation unless NewSQLStore is given another timeout
+const DefaultQueryTimeout = 5 * time.Second
+
+// SQLStore is an ItemStore backed by the SQL functions in this file.
+// The queries stick to SQL that Postgres and SQLite both understand.
+// Every operation runs under the context it is given, cut short by the store's query timeout.
+type SQLStore struct {
+	db      DBTX
+	timeout time.Duration
+	depth   int           // savepoint nesting inside a transaction; see WithinTx
+	observe QueryObserver // optional, see ObserveQueries
+}
 
-	return items, nil // Otherwise, return the list of items
+// QueryObserver is told the name and duration of every finished SQLStore operation, such as "GetItem"
+type QueryObserver func(operation string, elapsed time.Duration)
+
+// NewSQLStore returns an ItemStore that uses the given database connection and
+// aborts operations that take longer than timeout; zero means no timeout
+func NewSQLStore(db DBTX, timeout time.Duration) *SQLStore {
+	return &SQLStore{db: db, timeout: timeout}
+}
+
+// ObserveQueries reports the duration of every operation of the store, and of the stores
+// WithinTx hands out, to observe. It must be called before the store is used.
+func (s *SQLStore) ObserveQueries(observe QueryObserver) {
+	s.observe = observe
 }
 
+// queryContext derives the context of one store operation from ctx and, if ctx belongs to a trace,
+// starts its span. Cancelling it ends the span and reports the duration to the query observer.
+func (s *SQLStore) queryContext(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	ctx, span := startSpan(ctx, "SQLStore."+operation)
+	var cancel context.CancelFunc
+	if s.timeout <= 0 {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+	}
+	start := time.Now()
+	return ctx, func() {
+		if s.observe != nil {
+			s.observe(operation, time.Since(start))
+		}
+		span.End()
+		cancel()
+	}
+}
 
+// GetItem retrieves an item of a list by its ID from the database
+func (s *SQLStore) GetItem(ctx context.Context, listID, id string) (models.ShoppingItem, error) {
+	ctx, cancel := s.queryContext(ctx, "GetItem")
+	defer cancel()
+	item, err := GetItem(ctx, s.db, listID, id)
+	return item, contextError(ctx, err)
+}
+
+// GetItemByName retrieves an item of a list by its name from the database
+func (s *SQLStore) GetItemByName(ctx context.Context, listID, name string) (models.ShoppingItem, error) {
+	ctx, cancel := s.queryContext(ctx, "GetItemByName")
+	defer cancel()
+	item, err := GetItemByName(ctx, s.db, listID, name)
+	return item, contextError(ctx, err)
+}
+
+// ModifyItem atomically reads, changes and writes back a shopping item of a list
+func (s *SQLStore) ModifyItem(ctx context.Context, listID, id string, modify func(*models.ShoppingItem) error) (models.ShoppingItem, error) {
+	ctx, cancel := s.queryContext(ctx, "ModifyItem")
+	defer cancel()
+	item, err := ModifyItem(ctx, s.db, listID, id, modify)
+	return item, contextError(ctx, err)
+}
+
+// RenameItem changes the name of a shopping item
+func (s *SQLStore) RenameItem(ctx context.Context, listID, id, name string) error {
+	ctx, cancel := s.queryContext(ctx, "RenameItem")
+	defer cancel()
+	return contextError(ctx, RenameItem(ctx, s.db, listID, id, name))
+}
+
+// DeleteItem deletes a shopping item of a list from the database if it passes check
+func (s *SQLStore) DeleteItem(ctx context.Context, listID, id string, check ItemCheck) error {
+	ctx, cancel := s.queryContext(ctx, "DeleteItem")
+	defer cancel()
+	return contextError(ctx, DeleteItem(ctx, s.db, listID, id, check))
+}
+
+// GetAllItems retrieves one page of the shopping items of a list that match the query from the database
+func (s *SQLStore) GetAllItems(ctx context.Context, listID string, query ItemQuery) (ItemPage, error) {
+	ctx, cancel := s.queryContext(ctx, "GetAllItems")
+	defer cancel()
+	page, err := GetAllItems(ctx, s.db, listID, query)
+	return page, contextError(ctx, err)
+}
+
+// CountItems counts the shopping items of all lists in the database
+func (s *SQLStore) CountItems(ctx context.Context) (int, error) {
+	ctx, cancel := s.queryContext(ctx, "CountItems")
+	defer cancel()
+	count, err := CountItems(ctx, s.db)
+	return count, contextError(ctx, err)
+}
+
+// AddItem adds a new shopping item to a list
+func (s *SQLStore) AddItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, error) {
+	ctx, cancel := s.queryContext(ctx, "AddItem")
+	defer cancel()
+	item, err := AddItem(ctx, s.db, listID, item)
+	return item, contextError(ctx, err)
+}
+
+// UpsertItem adds a shopping item to a list or merges it into the item with the same name
+func (s *SQLStore) UpsertItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, bool, error) {
+	ctx, cancel := s.queryContext(ctx, "UpsertItem")
+	defer cancel()
+	item, created, err := UpsertItem(ctx, s.db, listID, item)
+	return item, created, contextError(ctx, err)
+}
+
+// AdjustItemAmount atomically adds delta to the amount of a shopping item of a list
+func (s *SQLStore) AdjustItemAmount(ctx context.Context, listID, id string, delta int, policy ZeroPolicy) (models.ShoppingItem, bool, error) {
+	ctx, cancel := s.queryContext(ctx, "AdjustItemAmount")
+	defer cancel()
+	item, deleted, err := AdjustItemAmount(ctx, s.db, listID, id, delta, policy)
+	return item, deleted, contextError(ctx, err)
+}
+
+// GetAllLists retrieves all shopping lists from the database
+func (s *SQLStore) GetAllLists(ctx context.Context) ([]models.ShoppingList, error) {
+	ctx, cancel := s.queryContext(ctx, "GetAllLists")
+	defer cancel()
+	lists, err := GetAllLists(ctx, s.db)
+	return lists, contextError(ctx, err)
+}
+
+// GetList retrieves a shopping list by its ID from the database
+func (s *SQLStore) GetList(ctx context.Context, id string) (models.ShoppingList, error) {
+	ctx, cancel := s.queryContext(ctx, "GetList")
+	defer cancel()
+	list, err := GetList(ctx, s.db, id)
+	return list, contextError(ctx, err)
+}
+
+// CreateList creates a new shopping list with a generated ID
+func (s *SQLStore) CreateList(ctx context.Context, name string) (models.ShoppingList, error) {
+	ctx, cancel := s.queryContext(ctx, "CreateList")
+	defer cancel()
+	list, err := CreateList(ctx, s.db, name)
+	return list, contextError(ctx, err)
+}
+
+// RenameList changes the name of a shopping list
+func (s *SQLStore) RenameList(ctx context.Context, id, name string) error {
+	ctx, cancel := s.queryContext(ctx, "RenameList")
+	defer cancel()
+	return contextError(ctx, RenameList(ctx, s.db, id, name))
+}
 
-// AddItem adds a new shopping item to the list
-func AddItem(db *sql.DB, item models.ShoppingItem) error {
-	_, err := db.Exec("INSERT INTO shopping_items (name, amount) VALUES ($1, $2)", item.Name, item.Amount)
-	return err
+// DeleteList deletes a shopping list together with its items
+func (s *SQLStore) DeleteList(ctx context.Context, id string) error {
+	ctx, cancel := s.queryContext(ctx, "DeleteList")
+	defer cancel()
+	return contextError(ctx, DeleteList(ctx, s.db, id))
 }
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"shopping-api-backend-go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// DefaultListID is the list behind the /api/shoppingItems routes.
+// It is created by the migrations and cannot be deleted.
+const DefaultListID = "default"
+
+// errDefaultList is returned when trying to delete the default list
+var errDefaultList = newError(ErrConflict, "default_list_protected", "the default list cannot be deleted")
+
+// GetAllLists retrieves all shopping lists from the database
+func GetAllLists(ctx context.Context, db DBTX) ([]models.ShoppingList, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, name FROM shopping_lists ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []models.ShoppingList{}
+	for rows.Next() {
+		var list models.ShoppingList
+		if err := rows.Scan(&list.ID, &list.Name); err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, rows.Err()
+}
+
+// GetList retrieves a shopping list by its ID from the database
+func GetList(ctx context.Context, db DBTX, id string) (models.ShoppingList, error) {
+	var list models.ShoppingList
+	err := db.QueryRowContext(ctx, "SELECT id, name FROM shopping_lists WHERE id = $1", id).Scan(&list.ID, &list.Name)
+	return list, dbError(err, "list")
+}
+
+// CreateList creates a new shopping list with a generated ID
+func CreateList(ctx context.Context, db DBTX, name string) (models.ShoppingList, error) {
+	list := models.ShoppingList{ID: uuid.NewString(), Name: name}
+	_, err := db.ExecContext(ctx, "INSERT INTO shopping_lists (id, name) VALUES ($1, $2)", list.ID, list.Name)
+	return list, dbError(err, "list")
+}
+
+// RenameList changes the name of a shopping list
+func RenameList(ctx context.Context, db DBTX, id, name string) error {
+	result, err := db.ExecContext(ctx, "UPDATE shopping_lists SET name = $1 WHERE id = $2", name, id)
+	return requireRowsAffected(result, err, "list")
+}
+
+// DeleteList deletes a shopping list; its items are removed by the foreign key cascade
+// and recorded as deleted in their history
+func DeleteList(ctx context.Context, db DBTX, id string) error {
+	if id == DefaultListID {
+		return errDefaultList
+	}
+	return withTx(ctx, db, func(tx DBTX) error {
+		items, err := listItems(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := recordChange(ctx, tx, id, models.ItemDeleted, &item, nil); err != nil {
+				return err
+			}
+		}
+		result, err := tx.ExecContext(ctx, "DELETE FROM shopping_lists WHERE id = $1", id)
+		return requireRowsAffected(result, err, "list")
+	})
+}
+
+// listItems retrieves every item of a list from the database
+func listItems(ctx context.Context, db DBTX, listID string) ([]models.ShoppingItem, error) {
+	rows, err := db.QueryContext(ctx, "SELECT "+itemColumns+" FROM shopping_items WHERE list_id = $1", listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.ShoppingItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
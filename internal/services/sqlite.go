@@ -0,0 +1,39 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// SQLiteScheme is the DSN prefix that selects the embedded SQLite backend
+const SQLiteScheme = "sqlite://"
+
+// OpenSQLite opens the SQLite database named by a DSN such as sqlite:///var/lib/shopping.db.
+// The queries in shopping_item_service.go run unchanged on it; its schema comes from the sqlite migrations.
+func OpenSQLite(dsn string) (*sql.DB, error) {
+	path := strings.TrimPrefix(dsn, SQLiteScheme)
+	if path == "" {
+		return nil, fmt.Errorf("sqlite DSN %q has no database path", dsn)
+	}
+
+	// Enforce foreign keys and wait for locks instead of failing with SQLITE_BUSY
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	path += sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	db, err := openDB("sqlite", path, semconv.DBSystemSqlite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// SQLite allows a single writer, so serialize access through one connection.
+	// This also keeps ":memory:" databases from being split across connections.
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"shopping-api-backend-go/internal/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// ItemStore is the storage backend used by the shopping item and list handlers.
+// Items always belong to a list and are addressed by their generated ID;
+// the /api/shoppingItems routes look them up by name in DefaultListID.
+// Operations stop when their context is cancelled and then fail with ErrTimeout or ErrUnavailable.
+// Every change of an item is recorded in its history together with the actor of the context; see WithActor.
+type ItemStore interface {
+	GetItem(ctx context.Context, listID, id string) (models.ShoppingItem, error)
+	GetItemByName(ctx context.Context, listID, name string) (models.ShoppingItem, error)
+	ModifyItem(ctx context.Context, listID, id string, modify func(*models.ShoppingItem) error) (models.ShoppingItem, error)
+	RenameItem(ctx context.Context, listID, id, name string) error
+	DeleteItem(ctx context.Context, listID, id string, check ItemCheck) error
+	GetAllItems(ctx context.Context, listID string, query ItemQuery) (ItemPage, error)
+	CountItems(ctx context.Context) (int, error) // items of all lists together
+	AddItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, error)
+	UpsertItem(ctx context.Context, listID string, item models.ShoppingItem) (models.ShoppingItem, bool, error)
+	AdjustItemAmount(ctx context.Context, listID, id string, delta int, policy ZeroPolicy) (models.ShoppingItem, bool, error)
+	// GetItemHistory returns the recorded changes of every item of a list that has or had the name, oldest first
+	GetItemHistory(ctx context.Context, listID, name string) ([]models.ItemChange, error)
+
+	GetAllLists(ctx context.Context) ([]models.ShoppingList, error)
+	GetList(ctx context.Context, id string) (models.ShoppingList, error)
+	CreateList(ctx context.Context, name string) (models.ShoppingList, error)
+	RenameList(ctx context.Context, id, name string) error
+	DeleteList(ctx context.Context, id string) error
+
+	// WithinTx runs fn with a store whose operations all belong to one transaction, committed if fn
+	// returns nil and rolled back otherwise. Nested calls roll back only their own changes on failure.
+	WithinTx(ctx context.Context, fn func(tx ItemStore) error) error
+}
+
+// ItemCheck inspects the current state of an item before a conditional write.
+// Returning an error aborts the write; a nil ItemCheck always passes.
+type ItemCheck func(item models.ShoppingItem) error
+
+// MaxItemAmount is the largest amount an item may have, matching the binding tag of ShoppingItem.Amount
+const MaxItemAmount = 10000
+
+// ZeroPolicy decides what AdjustItemAmount does with an item whose amount drops to zero or below
+type ZeroPolicy string
+
+// Zero policies
+const (
+	DeleteAtZero ZeroPolicy = "delete" // the item is deleted
+	ClampAtZero  ZeroPolicy = "clamp"  // the item is kept with an amount of zero
+)
+
+// errAmountLimit rejects changes that would push an amount past MaxItemAmount
+var errAmountLimit = newError(ErrConflict, "amount_limit_exceeded", "the amount cannot exceed %d", MaxItemAmount)
+
+// ItemFilter narrows GetAllItems down to matching items; zero fields match everything
+type ItemFilter struct {
+	Name       string
+	NamePrefix string
+	Unit       string
+	Category   string
+	Checked    *bool
+	MinAmount  *int
+	MaxAmount  *int
+}
+
+// Matches reports whether an item passes the filter
+func (f ItemFilter) Matches(item models.ShoppingItem) bool {
+	return (f.Name == "" || item.Name == f.Name) &&
+		strings.HasPrefix(item.Name, f.NamePrefix) &&
+		(f.Unit == "" || item.Unit == f.Unit) &&
+		(f.Category == "" || item.Category == f.Category) &&
+		(f.Checked == nil || item.Checked == *f.Checked) &&
+		(f.MinAmount == nil || item.Amount >= *f.MinAmount) &&
+		(f.MaxAmount == nil || item.Amount <= *f.MaxAmount)
+}
+
+// where collects the SQL conditions selecting the filtered items of a list
+func (f ItemFilter) where(listID string) *conditions {
+	where := &conditions{}
+	where.add("list_id = $%d", listID)
+	if f.Name != "" {
+		where.add("name = $%d", f.Name)
+	}
+	if f.NamePrefix != "" {
+		// substr works on characters in both Postgres and SQLite, unlike LIKE which differs in case sensitivity
+		where.add("substr(name, 1, $%d) = $%d", utf8.RuneCountInString(f.NamePrefix), f.NamePrefix)
+	}
+	if f.Unit != "" {
+		where.add("unit = $%d", f.Unit)
+	}
+	if f.Category != "" {
+		where.add("category = $%d", f.Category)
+	}
+	if f.Checked != nil {
+		where.add("checked = $%d", *f.Checked)
+	}
+	if f.MinAmount != nil {
+		where.add("amount >= $%d", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		where.add("amount <= $%d", *f.MaxAmount)
+	}
+	return where
+}
+
+// conditions builds a WHERE clause with numbered placeholders
+type conditions struct {
+	conds []string
+	args  []interface{}
+}
+
+// add appends a condition; each %d in cond is replaced by the placeholder number of the matching arg
+func (c *conditions) add(cond string, args ...interface{}) {
+	nums := make([]interface{}, len(args))
+	for i := range args {
+		nums[i] = len(c.args) + i + 1
+	}
+	c.args = append(c.args, args...)
+	c.conds = append(c.conds, fmt.Sprintf(cond, nums...))
+}
+
+// String joins the conditions with AND
+func (c *conditions) String() string {
+	return strings.Join(c.conds, " AND ")
+}
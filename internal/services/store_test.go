@@ -0,0 +1,289 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	dbmigrate "shopping-api-backend-go/db"
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+
+	"github.com/pressly/goose/v3"
+)
+
+// testStore is a store under test together with its name in subtests
+type testStore struct {
+	name  string
+	store services.ItemStore
+}
+
+// newSQLiteStore returns a SQLStore on a migrated SQLite database in a temporary directory
+func newSQLiteStore(t *testing.T) *services.SQLStore {
+	t.Helper()
+	return services.NewSQLStore(openSQLite(t), 0)
+}
+
+// openSQLite opens a migrated SQLite database in a temporary directory
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := services.OpenSQLite(services.SQLiteScheme + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	goose.SetLogger(goose.NopLogger())
+	if err := dbmigrate.RunMigrations(conn, dbmigrate.DialectSQLite); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// testStores returns a fresh MemoryStore and SQLite store, so every test covers both backends
+func testStores(t *testing.T) []testStore {
+	return []testStore{
+		{"memory", services.NewMemoryStore()},
+		{"sqlite", newSQLiteStore(t)},
+	}
+}
+
+// addItems adds items to the default list and fails the test if one can't be added
+func addItems(t *testing.T, store services.ItemStore, items ...models.ShoppingItem) []models.ShoppingItem {
+	t.Helper()
+	added := make([]models.ShoppingItem, len(items))
+	for i, item := range items {
+		var err error
+		if added[i], err = store.AddItem(context.Background(), services.DefaultListID, item); err != nil {
+			t.Fatalf("adding %s: %v", item.Name, err)
+		}
+	}
+	return added
+}
+
+// itemNames returns the names of items in order
+func itemNames(items []models.ShoppingItem) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+func TestItemStore(t *testing.T) {
+	for _, s := range testStores(t) {
+		t.Run(s.name, func(t *testing.T) {
+			ctx := context.Background()
+			list := services.DefaultListID
+			added := addItems(t, s.store, models.ShoppingItem{Name: "Milk", Amount: 1}, models.ShoppingItem{Name: "Bread", Amount: 2})
+			milk, bread := added[0], added[1]
+			if milk.ID == "" || milk.ID == bread.ID {
+				t.Fatalf("added items got IDs %q and %q, want distinct ones", milk.ID, bread.ID)
+			}
+			if _, err := s.store.AddItem(ctx, list, models.ShoppingItem{Name: "Milk", Amount: 3}); !errors.Is(err, services.ErrAlreadyExists) {
+				t.Errorf("adding Milk twice = %v, want ErrAlreadyExists", err)
+			}
+
+			milk, err := s.store.ModifyItem(ctx, list, milk.ID, func(item *models.ShoppingItem) error {
+				item.Amount = 4
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			item, err := s.store.GetItemByName(ctx, list, "Milk")
+			if err != nil || item.Amount != 4 || item.ID != milk.ID {
+				t.Errorf("GetItemByName(Milk) = %+v, %v; want %+v", item, err, milk)
+			}
+
+			// Renaming keeps the ID but can't take the name of another item
+			if err := s.store.RenameItem(ctx, list, milk.ID, "Bread"); !errors.Is(err, services.ErrConflict) {
+				t.Errorf("RenameItem(Milk, Bread) = %v, want ErrConflict", err)
+			}
+			if err := s.store.RenameItem(ctx, list, milk.ID, "Oat milk"); err != nil {
+				t.Fatal(err)
+			}
+			if item, err := s.store.GetItem(ctx, list, milk.ID); err != nil || item.Name != "Oat milk" {
+				t.Errorf("GetItem(%s) = %+v, %v; want Oat milk", milk.ID, item, err)
+			}
+
+			if err := s.store.DeleteItem(ctx, list, bread.ID, nil); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.store.GetItem(ctx, list, bread.ID); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("GetItem(Bread) after deleting it = %v, want ErrNotFound", err)
+			}
+			if _, err := s.store.ModifyItem(ctx, list, bread.ID, func(*models.ShoppingItem) error { return nil }); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("ModifyItem(Bread) after deleting it = %v, want ErrNotFound", err)
+			}
+			if err := s.store.DeleteItem(ctx, list, bread.ID, nil); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("DeleteItem(Bread) twice = %v, want ErrNotFound", err)
+			}
+
+			addItems(t, s.store, models.ShoppingItem{Name: "Apples", Amount: 6})
+			page, err := s.store.GetAllItems(ctx, list, services.ItemQuery{Sort: services.ItemSort{Field: "name"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := itemNames(page.Items)
+			if want := []string{"Apples", "Oat milk"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("GetAllItems() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// TestListsScopeItems checks that items of different lists don't clash and go away with their list
+func TestListsScopeItems(t *testing.T) {
+	for _, s := range testStores(t) {
+		t.Run(s.name, func(t *testing.T) {
+			ctx := context.Background()
+			list, err := s.store.CreateList(ctx, "Household")
+			if err != nil {
+				t.Fatal(err)
+			}
+			addItems(t, s.store, models.ShoppingItem{Name: "Milk", Amount: 1})
+			if _, err := s.store.AddItem(ctx, list.ID, models.ShoppingItem{Name: "Milk", Amount: 2}); err != nil {
+				t.Fatalf("adding Milk to a second list: %v", err)
+			}
+			item, err := s.store.GetItemByName(ctx, list.ID, "Milk")
+			if err != nil || item.Amount != 2 {
+				t.Errorf("GetItemByName(list, Milk) = %+v, %v; want amount 2", item, err)
+			}
+
+			if err := s.store.DeleteList(ctx, list.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.store.GetItemByName(ctx, list.ID, "Milk"); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("the item of a deleted list: %v, want ErrNotFound", err)
+			}
+			if _, err := s.store.AddItem(ctx, list.ID, models.ShoppingItem{Name: "Milk", Amount: 1}); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("adding to a deleted list = %v, want ErrNotFound", err)
+			}
+			if _, err := s.store.GetItemByName(ctx, services.DefaultListID, "Milk"); err != nil {
+				t.Errorf("the item of the default list is gone: %v", err)
+			}
+			if err := s.store.DeleteList(ctx, services.DefaultListID); !errors.Is(err, services.ErrConflict) {
+				t.Errorf("DeleteList(default) = %v, want ErrConflict", err)
+			}
+		})
+	}
+}
+
+func TestItemFilter(t *testing.T) {
+	price := 1.29
+	checkedAt := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		filter services.ItemFilter
+		want   []string
+	}{
+		{"everything", services.ItemFilter{}, []string{"Bread", "Cheese", "Milk"}},
+		{"name", services.ItemFilter{Name: "Milk"}, []string{"Milk"}},
+		{"unit", services.ItemFilter{Unit: "l"}, []string{"Milk"}},
+		{"category", services.ItemFilter{Category: "dairy"}, []string{"Cheese", "Milk"}},
+		{"checked", services.ItemFilter{Checked: &yes}, []string{"Cheese"}},
+		{"unchecked dairy", services.ItemFilter{Category: "dairy", Checked: &no}, []string{"Milk"}},
+	}
+	for _, s := range testStores(t) {
+		t.Run(s.name, func(t *testing.T) {
+			ctx := context.Background()
+			added := addItems(t, s.store,
+				models.ShoppingItem{Name: "Milk", Amount: 2, Unit: "l", Category: "dairy", Notes: "Lactose free", Price: &price},
+				models.ShoppingItem{Name: "Cheese", Amount: 1, Category: "dairy", Checked: true, CheckedAt: &checkedAt},
+				models.ShoppingItem{Name: "Bread", Amount: 1},
+			)
+			milk, err := s.store.GetItem(ctx, services.DefaultListID, added[0].ID)
+			if err != nil || milk.Notes != "Lactose free" || milk.Price == nil || *milk.Price != price {
+				t.Errorf("GetItem(Milk) = %+v, %v; want the notes and price it was added with", milk, err)
+			}
+			cheese, err := s.store.GetItem(ctx, services.DefaultListID, added[1].ID)
+			if err != nil || cheese.CheckedAt == nil || !cheese.CheckedAt.Equal(checkedAt) {
+				t.Errorf("GetItem(Cheese) = %+v, %v; want checkedAt %s", cheese, err, checkedAt)
+			}
+
+			for _, tt := range tests {
+				page, err := s.store.GetAllItems(ctx, services.DefaultListID, services.ItemQuery{ItemFilter: tt.filter, Sort: services.ItemSort{Field: "name"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := itemNames(page.Items)
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: GetAllItems() = %v, want %v", tt.name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAdjustItemAmount(t *testing.T) {
+	for _, s := range testStores(t) {
+		t.Run(s.name, func(t *testing.T) {
+			ctx := context.Background()
+			list := services.DefaultListID
+			milk := addItems(t, s.store, models.ShoppingItem{Name: "Milk", Amount: 2})[0]
+
+			item, deleted, err := s.store.AdjustItemAmount(ctx, list, milk.ID, 3, services.DeleteAtZero)
+			if err != nil || deleted || item.Amount != 5 || item.Version != milk.Version+1 {
+				t.Errorf("incrementing by 3 = %+v, %t, %v; want amount 5 at the next version", item, deleted, err)
+			}
+			if _, _, err := s.store.AdjustItemAmount(ctx, list, milk.ID, 9996, services.DeleteAtZero); !errors.Is(err, services.ErrConflict) {
+				t.Errorf("incrementing past 10000 = %v, want ErrConflict", err)
+			}
+			if item, deleted, err := s.store.AdjustItemAmount(ctx, list, milk.ID, -9, services.ClampAtZero); err != nil || deleted || item.Amount != 0 {
+				t.Errorf("decrementing below zero with clamp = %+v, %t, %v; want amount 0", item, deleted, err)
+			}
+			if _, deleted, err := s.store.AdjustItemAmount(ctx, list, milk.ID, -1, services.DeleteAtZero); err != nil || !deleted {
+				t.Errorf("decrementing at zero with delete = %t, %v; want deleted", deleted, err)
+			}
+			if _, err := s.store.GetItem(ctx, list, milk.ID); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("GetItem(Milk) after deleting it at zero = %v, want ErrNotFound", err)
+			}
+			if _, _, err := s.store.AdjustItemAmount(ctx, list, milk.ID, 1, services.DeleteAtZero); !errors.Is(err, services.ErrNotFound) {
+				t.Errorf("incrementing a deleted item = %v, want ErrNotFound", err)
+			}
+
+			// Upserting adds the amounts of items with the same name
+			bread := addItems(t, s.store, models.ShoppingItem{Name: "Bread", Amount: 1, Unit: "loaf"})[0]
+			item, created, err := s.store.UpsertItem(ctx, list, models.ShoppingItem{Name: "Bread", Amount: 2, Category: "bakery"})
+			if err != nil || created || item.ID != bread.ID || item.Amount != 3 || item.Unit != "loaf" || item.Category != "bakery" {
+				t.Errorf("upserting Bread = %+v, %t, %v; want it merged into %+v", item, created, err, bread)
+			}
+			if item, created, err := s.store.UpsertItem(ctx, list, models.ShoppingItem{Name: "Eggs", Amount: 6}); err != nil || !created || item.ID == "" {
+				t.Errorf("upserting Eggs = %+v, %t, %v; want it created", item, created, err)
+			}
+		})
+	}
+}
+
+func TestQueryTimeout(t *testing.T) {
+	conn := openSQLite(t)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		ctx      context.Context
+		wantErr  error
+		wantCode string
+	}{
+		{"query timeout", time.Nanosecond, context.Background(), services.ErrTimeout, "query_timeout"},
+		{"cancelled request", 0, cancelled, services.ErrUnavailable, "request_cancelled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := services.NewSQLStore(conn, tt.timeout)
+			_, err := store.GetAllItems(tt.ctx, services.DefaultListID, services.ItemQuery{Sort: services.ItemSort{Field: "name"}})
+			if !errors.Is(err, tt.wantErr) || services.ErrorCode(err) != tt.wantCode {
+				t.Errorf("GetAllItems() = %v (code %q), want %v with code %s", err, services.ErrorCode(err), tt.wantErr, tt.wantCode)
+			}
+		})
+	}
+	if _, err := services.NewSQLStore(conn, time.Minute).GetAllLists(context.Background()); err != nil {
+		t.Errorf("GetAllLists() with a minute to spare = %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of the store operations
+var tracer = otel.Tracer("shopping-api-backend-go/internal/services")
+
+// startSpan starts a child span of the span in ctx. Operations outside a trace, such as
+// the counts behind the metrics, get no span of their own.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, name)
+}
+
+// openDB opens a database whose statements are traced as children of the span in their context.
+// Statements without a span, such as migrations and pool upkeep, are not traced.
+func openDB(driverName, dsn string, system attribute.KeyValue) (*sql.DB, error) {
+	return otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(system),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			OmitConnectorConnect: true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+}
+
+// recordError adds err to the span in ctx. Errors a client can cause, such as ErrNotFound,
+// are expected outcomes and keep the span status; anything else marks the span as failed.
+func recordError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(attribute.String("error.code", ErrorCode(err))))
+	for _, kind := range []error{ErrNotFound, ErrAlreadyExists, ErrValidation, ErrConflict, ErrPreconditionFailed} {
+		if errors.Is(err, kind) {
+			return
+		}
+	}
+	span.SetStatus(codes.Error, err.Error())
+}
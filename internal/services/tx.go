@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX is implemented by both *sql.DB and *sql.Tx, so the SQL functions of this package
+// run either on their own or as part of a larger transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// withTx runs fn in a transaction. On a *sql.DB it begins one and commits it if fn succeeds;
+// on a *sql.Tx fn simply joins the transaction that is already running.
+// A transaction begun here is rolled back when ctx is cancelled.
+func withTx(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	return withTxOptions(ctx, db, nil, fn)
+}
+
+// withSnapshot runs fn in a read-only transaction in which every statement sees the same snapshot
+// of the database, so reads spread over several statements are consistent with each other. On a
+// *sql.Tx fn joins the running transaction and sees what its isolation level allows.
+func withSnapshot(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	return withTxOptions(ctx, db, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+}
+
+// withTxOptions is withTx for a transaction begun with opts
+func withTxOptions(ctx context.Context, db DBTX, opts *sql.TxOptions, fn func(tx DBTX) error) error {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withSavepoint runs fn inside a savepoint of the transaction tx and rolls back only fn's changes if it fails.
+// This keeps the enclosing transaction usable after a failed statement, which Postgres otherwise aborts.
+func withSavepoint(ctx context.Context, tx DBTX, name string, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rolling back savepoint: %v)", err, rbErr)
+		}
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// WithinTx runs fn with a store whose operations all belong to one transaction, which is committed
+// if fn returns nil and rolled back otherwise. Nested calls use savepoints, so an inner failure only
+// undoes the changes made inside it. The transaction is rolled back when ctx is cancelled.
+func (s *SQLStore) WithinTx(ctx context.Context, fn func(tx ItemStore) error) error {
+	ctx, span := startSpan(ctx, "SQLStore.WithinTx")
+	defer span.End()
+	if _, ok := s.db.(*sql.DB); !ok {
+		nested := &SQLStore{db: s.db, timeout: s.timeout, depth: s.depth + 1, observe: s.observe}
+		err := withSavepoint(ctx, s.db, fmt.Sprintf("sp_%d", nested.depth), func() error { return fn(nested) })
+		return contextError(ctx, err)
+	}
+	err := withTx(ctx, s.db, func(tx DBTX) error { return fn(&SQLStore{db: tx, timeout: s.timeout, observe: s.observe}) })
+	return contextError(ctx, err)
+}
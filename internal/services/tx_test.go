@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"shopping-api-backend-go/internal/models"
+	"shopping-api-backend-go/internal/services"
+)
+
+// TestWithinTx checks that a failed transaction undoes everything and that a failed nested one
+// only undoes its own changes, while the enclosing transaction goes on and commits
+func TestWithinTx(t *testing.T) {
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name     string
+		innerErr error
+		outerErr error
+		failSQL  bool            // the nested transaction fails on a statement, adding Outer again
+		want     map[string]bool // item name -> whether it is stored afterwards
+	}{
+		{"both commit", nil, nil, false, map[string]bool{"Outer": true, "Inner": true, "After": true}},
+		{"inner rolls back", errAbort, nil, false, map[string]bool{"Outer": true, "Inner": false, "After": true}},
+		{"inner statement fails", services.ErrAlreadyExists, nil, true, map[string]bool{"Outer": true, "Inner": false, "After": true}},
+		{"outer rolls back", nil, errAbort, false, map[string]bool{"Outer": false, "Inner": false, "After": false}},
+	}
+	for _, s := range testStores(t) {
+		for _, tt := range tests {
+			t.Run(s.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				name := func(item string) string { return tt.name + " " + item }
+				err := s.store.WithinTx(ctx, func(tx services.ItemStore) error {
+					if _, err := tx.AddItem(ctx, services.DefaultListID, models.ShoppingItem{Name: name("Outer"), Amount: 1}); err != nil {
+						return err
+					}
+					err := tx.WithinTx(ctx, func(inner services.ItemStore) error {
+						if _, err := inner.AddItem(ctx, services.DefaultListID, models.ShoppingItem{Name: name("Inner"), Amount: 1}); err != nil {
+							return err
+						}
+						if tt.failSQL {
+							_, err := inner.AddItem(ctx, services.DefaultListID, models.ShoppingItem{Name: name("Outer"), Amount: 1})
+							return err
+						}
+						return tt.innerErr
+					})
+					if !errors.Is(err, tt.innerErr) {
+						t.Errorf("nested WithinTx() = %v, want %v", err, tt.innerErr)
+					}
+					// The transaction stays usable after the nested one failed
+					if _, err := tx.AddItem(ctx, services.DefaultListID, models.ShoppingItem{Name: name("After"), Amount: 1}); err != nil {
+						return err
+					}
+					return tt.outerErr
+				})
+				if !errors.Is(err, tt.outerErr) {
+					t.Fatalf("WithinTx() = %v, want %v", err, tt.outerErr)
+				}
+
+				for item, want := range tt.want {
+					_, err := s.store.GetItemByName(ctx, services.DefaultListID, name(item))
+					if got := err == nil; got != want {
+						t.Errorf("%s stored = %v, want %v (error %v)", item, got, want, err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package migrations embeds the goose SQL migrations so they ship inside the binary.
+package migrations
+
+import "embed"
+
+// FS holds the migrations, one directory per SQL dialect
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shopping_lists (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+-- Existing items move into the default list
+INSERT INTO shopping_lists (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE shopping_items
+    ADD COLUMN list_id TEXT NOT NULL DEFAULT 'default' REFERENCES shopping_lists (id) ON DELETE CASCADE;
+ALTER TABLE shopping_items ALTER COLUMN list_id DROP DEFAULT;
+
+-- Item names are unique per list instead of globally
+ALTER TABLE shopping_items DROP CONSTRAINT shopping_items_pkey;
+ALTER TABLE shopping_items ADD PRIMARY KEY (list_id, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM shopping_items WHERE list_id <> 'default';
+ALTER TABLE shopping_items DROP CONSTRAINT shopping_items_pkey;
+ALTER TABLE shopping_items ADD PRIMARY KEY (name);
+ALTER TABLE shopping_items DROP COLUMN list_id;
+DROP TABLE IF EXISTS shopping_lists;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shopping_items ADD COLUMN id TEXT;
+UPDATE shopping_items SET id = gen_random_uuid()::text;
+ALTER TABLE shopping_items ALTER COLUMN id SET NOT NULL;
+
+-- Items are addressed by ID; names stay unique per list
+ALTER TABLE shopping_items DROP CONSTRAINT shopping_items_pkey;
+ALTER TABLE shopping_items ADD PRIMARY KEY (id);
+ALTER TABLE shopping_items ADD CONSTRAINT shopping_items_list_id_name_key UNIQUE (list_id, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shopping_items DROP CONSTRAINT shopping_items_list_id_name_key;
+ALTER TABLE shopping_items DROP CONSTRAINT shopping_items_pkey;
+ALTER TABLE shopping_items ADD PRIMARY KEY (list_id, name);
+ALTER TABLE shopping_items DROP COLUMN id;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shopping_items
+    ADD COLUMN unit TEXT NOT NULL DEFAULT '',
+    ADD COLUMN category TEXT NOT NULL DEFAULT '',
+    ADD COLUMN notes TEXT NOT NULL DEFAULT '',
+    ADD COLUMN price NUMERIC(10, 2),
+    ADD COLUMN checked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN checked_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shopping_items
+    DROP COLUMN unit,
+    DROP COLUMN category,
+    DROP COLUMN notes,
+    DROP COLUMN price,
+    DROP COLUMN checked,
+    DROP COLUMN checked_at;
+-- +goose StatementEnd
@@ -1,9 +1,9 @@
 -- +goose Up
 -- +goose StatementBegin
-SELECT 'up SQL query';
+ALTER TABLE shopping_items ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
 -- +goose StatementEnd
 
 -- +goose Down
 -- +goose StatementBegin
-SELECT 'down SQL query';
+ALTER TABLE shopping_items DROP COLUMN version;
 -- +goose StatementEnd
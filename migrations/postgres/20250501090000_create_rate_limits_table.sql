@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS rate_limits (
+    bucket_key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_unix DOUBLE PRECISION NOT NULL,
+    full_unix DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX IF NOT EXISTS rate_limits_full_unix_idx ON rate_limits (full_unix);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rate_limits;
+-- +goose StatementEnd
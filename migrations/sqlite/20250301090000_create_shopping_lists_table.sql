@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shopping_lists (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+-- Existing items move into the default list
+INSERT INTO shopping_lists (id, name) VALUES ('default', 'Default');
+
+-- SQLite cannot change a primary key in place, so rebuild the table
+-- with item names unique per list instead of globally
+CREATE TABLE shopping_items_new (
+    list_id TEXT NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    PRIMARY KEY (list_id, name)
+);
+INSERT INTO shopping_items_new (list_id, name, amount)
+    SELECT 'default', name, amount FROM shopping_items;
+DROP TABLE shopping_items;
+ALTER TABLE shopping_items_new RENAME TO shopping_items;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE shopping_items_old (
+    name TEXT PRIMARY KEY,
+    amount INTEGER NOT NULL
+);
+INSERT INTO shopping_items_old (name, amount)
+    SELECT name, amount FROM shopping_items WHERE list_id = 'default';
+DROP TABLE shopping_items;
+ALTER TABLE shopping_items_old RENAME TO shopping_items;
+DROP TABLE IF EXISTS shopping_lists;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Items are addressed by ID; names stay unique per list
+CREATE TABLE shopping_items_new (
+    id TEXT PRIMARY KEY,
+    list_id TEXT NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    UNIQUE (list_id, name)
+);
+
+-- Give existing items a random version 4 UUID
+INSERT INTO shopping_items_new (id, list_id, name, amount)
+    SELECT lower(
+               hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' ||
+               substr(hex(randomblob(2)), 2) || '-' ||
+               substr('89ab', 1 + (abs(random()) % 4), 1) || substr(hex(randomblob(2)), 2) || '-' ||
+               hex(randomblob(6))
+           ),
+           list_id, name, amount
+    FROM shopping_items;
+DROP TABLE shopping_items;
+ALTER TABLE shopping_items_new RENAME TO shopping_items;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE shopping_items_old (
+    list_id TEXT NOT NULL REFERENCES shopping_lists (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    PRIMARY KEY (list_id, name)
+);
+INSERT INTO shopping_items_old (list_id, name, amount)
+    SELECT list_id, name, amount FROM shopping_items;
+DROP TABLE shopping_items;
+ALTER TABLE shopping_items_old RENAME TO shopping_items;
+-- +goose StatementEnd
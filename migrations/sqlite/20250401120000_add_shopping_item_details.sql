@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE shopping_items ADD COLUMN unit TEXT NOT NULL DEFAULT '';
+ALTER TABLE shopping_items ADD COLUMN category TEXT NOT NULL DEFAULT '';
+ALTER TABLE shopping_items ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE shopping_items ADD COLUMN price REAL;
+ALTER TABLE shopping_items ADD COLUMN checked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE shopping_items ADD COLUMN checked_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shopping_items DROP COLUMN unit;
+ALTER TABLE shopping_items DROP COLUMN category;
+ALTER TABLE shopping_items DROP COLUMN notes;
+ALTER TABLE shopping_items DROP COLUMN price;
+ALTER TABLE shopping_items DROP COLUMN checked;
+ALTER TABLE shopping_items DROP COLUMN checked_at;
+-- +goose StatementEnd
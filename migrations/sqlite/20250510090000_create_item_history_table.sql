@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per change of an item, with the item as JSON before and after it. Rows outlive
+-- their items and lists, so there is no foreign key. name is the item name before the change,
+-- or after it for creations, and finds the history of an item by any name it had.
+CREATE TABLE IF NOT EXISTS item_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_id TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    op TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    before_item TEXT,
+    after_item TEXT
+);
+CREATE INDEX IF NOT EXISTS item_history_item_id_idx ON item_history (item_id, changed_at);
+CREATE INDEX IF NOT EXISTS item_history_list_id_name_idx ON item_history (list_id, name);
+CREATE INDEX IF NOT EXISTS item_history_list_id_changed_at_idx ON item_history (list_id, changed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS item_history;
+-- +goose StatementEnd
@@ -1,17 +1,49 @@
 package utils
 
 import (
+	"net/http"
 	"shopping-api-backend-go/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RespondWithError sends a standardized error response
-func RespondWithError(c *gin.Context, code int, message string) {
-	c.JSON(code, models.ErrorResponse{Error: message})
+// ProblemContentType is the media type of RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// NewProblem returns the problem details for a status with a machine-readable code
+func NewProblem(status int, code, detail string) models.Problem {
+	return models.Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Code: code, Detail: detail}
+}
+
+// NewValidationProblem returns the problem details of a 400 listing the fields that failed validation
+func NewValidationProblem(fields []models.FieldError) models.Problem {
+	problem := NewProblem(http.StatusBadRequest, "validation_failed", "The request has invalid fields")
+	problem.Errors = fields
+	return problem
+}
+
+// RespondWithProblem sends a problem details error response and aborts the request.
+// Missing type, title and instance are filled in from the status and the request.
+func RespondWithProblem(c *gin.Context, problem models.Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.RequestURI()
+	}
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// RespondWithError sends a standardized error response with a machine-readable code
+func RespondWithError(c *gin.Context, status int, code, detail string) {
+	RespondWithProblem(c, NewProblem(status, code, detail))
 }
 
-// RespondWithSuccess sends a standardized success response with data
-func RespondWithSuccess(c *gin.Context, code int, data interface{}) {
-	c.JSON(code, data)
+// RespondWithValidationError sends a 400 listing the fields that failed validation
+func RespondWithValidationError(c *gin.Context, fields []models.FieldError) {
+	RespondWithProblem(c, NewValidationProblem(fields))
 }
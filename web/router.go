@@ -1,25 +1,110 @@
 package web
 
 import (
-	"database/sql"
 	"shopping-api-backend-go/internal/handlers"
+	"shopping-api-backend-go/internal/middleware"
+	"shopping-api-backend-go/internal/services"
+	"shopping-api-backend-go/internal/tracing"
 
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-// InitializeRouter initializes the routes and returns a Gin engine with all routes set up
-func InitializeRouter(db *sql.DB) *gin.Engine {
-	r := gin.Default()
+// exposedHeaders are the response headers browser clients may read. A wildcard would not work
+// for requests with credentials, so they are listed one by one.
+var exposedHeaders = []string{"ETag", "Link", "X-Total-Count", "X-Next-Cursor", "X-Request-ID", "Idempotent-Replayed",
+	"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
+
+// InitializeRouter initializes the routes and returns a Gin engine serving them with h.
+// Each engine only uses the dependencies of its handler, so several can run side by side.
+func InitializeRouter(h *handlers.Handler) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.Tracing(tracing.ServiceName), middleware.RequestID(), middleware.AccessLog(h.Logger))
+	if h.Metrics != nil {
+		r.Use(middleware.Metrics(h.Metrics))
+	}
+	r.Use(gin.CustomRecovery(h.Recover))
+
+	// Browsers may call the API from the configured origins
+	if len(h.Config.CORSOrigins) > 0 {
+		r.Use(cors.New(cors.Config{
+			AllowOrigins:     h.Config.CORSOrigins,
+			AllowWildcard:    true, // for origins such as https://*.app.github.dev
+			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD", "PATCH"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key", "If-Match", "If-None-Match", "X-Request-ID", "X-API-Key", "traceparent", "tracestate"},
+			ExposeHeaders:    exposedHeaders,
+			AllowCredentials: true,
+		}))
+	}
+
+	// Unknown routes and methods answer with problem details like every other error
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(handlers.NoRoute)
+	r.NoMethod(handlers.NoMethod)
+
+	// Clients over their request rate are turned away before any work is done
+	if h.RateLimiter != nil {
+		r.Use(middleware.RateLimit(h.RateLimiter, h.Config.RateLimit, h.Config.APIKeys, h.Logger))
+	}
+
+	// Item changes are recorded as made by the client of the request
+	r.Use(middleware.Actor(h.Config.APIKeys))
+
+	// Retried writes with the same Idempotency-Key replay the original response
+	if store, ok := h.Store.(services.IdempotencyStore); ok {
+		r.Use(middleware.Idempotency(store, h.Config.IdempotencyTTL, h.Config.APIKeys, h.Logger))
+	}
 
 	// Health Check Endpoint
-	r.GET("/health", handlers.HealthCheck)
-
-	// CRUD routes for shopping items
-	r.GET("/api/shoppingItems/:name", handlers.GetItemByName)
-	r.PUT("/api/shoppingItems/:name", handlers.UpdateItem)
-	r.DELETE("/api/shoppingItems/:name", handlers.DeleteItem)
-	r.GET("/api/shoppingItems", handlers.GetAllItems)
-	r.POST("/api/shoppingItems", handlers.AddItem)
+	r.GET("/health", h.HealthCheck)
+
+	// Kubernetes probes
+	r.GET("/livez", h.LiveCheck)
+	r.GET("/readyz", h.ReadyCheck)
+
+	// Prometheus metrics
+	if h.Metrics != nil {
+		r.GET("/metrics", gin.WrapH(h.Metrics.Handler()))
+	}
+
+	// CRUD routes for shopping items in the default list
+	r.GET("/api/shoppingItems/:name", h.GetItem)
+	r.PUT("/api/shoppingItems/:name", h.UpdateItem)
+	r.PATCH("/api/shoppingItems/:name", h.PatchItem)
+	r.DELETE("/api/shoppingItems/:name", h.DeleteItem)
+	r.POST("/api/shoppingItems/:name/rename", h.RenameItem)
+	r.POST("/api/shoppingItems/:name/increment", h.IncrementItem)
+	r.POST("/api/shoppingItems/:name/decrement", h.DecrementItem)
+	r.GET("/api/shoppingItems/:name/history", h.GetItemHistory)
+	r.GET("/api/shoppingItems", h.GetAllItems)
+	r.POST("/api/shoppingItems", h.AddItem)
+
+	// Gin has no literal colons in paths, so the ":batch" suffix arrives as a parameter
+	r.POST("/api/shoppingItems:action", func(c *gin.Context) {
+		if c.Param("action") == ":batch" {
+			h.BatchItems(c)
+		} else {
+			handlers.NoRoute(c)
+		}
+	})
+
+	// CRUD routes for shopping lists
+	r.GET("/api/lists", h.GetAllLists)
+	r.POST("/api/lists", h.CreateList)
+	r.GET("/api/lists/:listId", h.GetList)
+	r.PUT("/api/lists/:listId", h.RenameList)
+	r.DELETE("/api/lists/:listId", h.DeleteList)
+
+	// Shopping items scoped to a list
+	r.GET("/api/lists/:listId/items", h.GetAllListItems)
+	r.POST("/api/lists/:listId/items", h.AddListItem)
+	r.GET("/api/lists/:listId/items/:itemId", h.GetListItem)
+	r.PUT("/api/lists/:listId/items/:itemId", h.UpdateListItem)
+	r.PATCH("/api/lists/:listId/items/:itemId", h.PatchListItem)
+	r.DELETE("/api/lists/:listId/items/:itemId", h.DeleteListItem)
+	r.POST("/api/lists/:listId/items/:itemId/rename", h.RenameListItem)
+	r.POST("/api/lists/:listId/items/:itemId/increment", h.IncrementListItem)
+	r.POST("/api/lists/:listId/items/:itemId/decrement", h.DecrementListItem)
 
 	return r
 }